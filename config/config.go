@@ -7,23 +7,25 @@ import (
 )
 
 type configDefinition struct {
-	Port              int        `koanf:"port"`
-	GrpcPort          int        `koanf:"grpc_port"`
-	Webhooks          []Webhook  `koanf:"webhooks"`
-	Database          database   `koanf:"database"`
-	Logging           logging    `koanf:"logging"`
-	Sentry            sentry     `koanf:"sentry"`
-	Pyroscope         pyroscope  `koanf:"pyroscope"`
-	Prometheus        Prometheus `koanf:"prometheus"`
-	PokemonMemoryOnly bool       `koanf:"pokemon_memory_only"`
-	TestFortInMemory  bool       `koanf:"test_fort_in_memory"`
-	Cleanup           cleanup    `koanf:"cleanup"`
-	RawBearer         string     `koanf:"raw_bearer"`
-	ApiSecret         string     `koanf:"api_secret"`
-	Pvp               pvp        `koanf:"pvp"`
-	Koji              koji       `koanf:"koji"`
-	Tuning            tuning     `koanf:"tuning"`
-	ScanRules         []scanRule `koanf:"scan_rules"`
+	Port              int             `koanf:"port"`
+	GrpcPort          int             `koanf:"grpc_port"`
+	Webhooks          []Webhook       `koanf:"webhooks"`
+	Database          database        `koanf:"database"`
+	Logging           logging         `koanf:"logging"`
+	Sentry            sentry          `koanf:"sentry"`
+	Pyroscope         pyroscope       `koanf:"pyroscope"`
+	Prometheus        Prometheus      `koanf:"prometheus"`
+	PokemonMemoryOnly bool            `koanf:"pokemon_memory_only"`
+	TestFortInMemory  bool            `koanf:"test_fort_in_memory"`
+	Cleanup           cleanup         `koanf:"cleanup"`
+	RawBearer         string          `koanf:"raw_bearer"`
+	RawCredentials    []RawCredential `koanf:"raw_credentials"`
+	ApiSecret         string          `koanf:"api_secret"`
+	Pvp               pvp             `koanf:"pvp"`
+	Koji              koji            `koanf:"koji"`
+	Tuning            tuning          `koanf:"tuning"`
+	ScanRules         []scanRule      `koanf:"scan_rules"`
+	Recorder          recorder        `koanf:"recorder"`
 }
 
 func (configDefinition configDefinition) GetWebhookInterval() time.Duration {
@@ -102,6 +104,14 @@ type logging struct {
 	Compress   bool `koanf:"compress"`
 }
 
+type recorder struct {
+	Enabled    bool   `koanf:"enabled"`
+	Filename   string `koanf:"filename"`
+	MaxSize    int    `koanf:"max_size"`
+	MaxBackups int    `koanf:"max_backups"`
+	Compress   bool   `koanf:"compress"`
+}
+
 type database struct {
 	Addr     string `koanf:"address"`
 	User     string `koanf:"user"`
@@ -114,7 +124,27 @@ type tuning struct {
 	ExtendedTimeout    bool    `koanf:"extended_timeout"`
 	MaxPokemonResults  int     `koanf:"max_pokemon_results"`
 	MaxPokemonDistance float64 `koanf:"max_pokemon_distance"`
+	MaxFortResults     int     `koanf:"max_fort_results"`
+	MaxSpawnpoints     int     `koanf:"max_spawnpoints"`
 	ProfileRoutes      bool    `koanf:"profile_routes"`
+	DecodeWorkers      int     `koanf:"decode_workers"`
+	DecodeQueueSize    int     `koanf:"decode_queue_size"`
+	DuplicateWindow    int     `koanf:"duplicate_window"`
+	DeadLetterMax      int     `koanf:"dead_letter_max"`
+	RawMaxBodySize     int     `koanf:"raw_max_body_size"`
+	MaxProtoAge        int     `koanf:"max_proto_age"`
+	ClockSkew          int     `koanf:"clock_skew"`
+	DeviceSilentMins   int     `koanf:"device_silent_minutes"`
+	EncounterLeaseSecs int     `koanf:"encounter_lease_seconds"`
+}
+
+type RawCredential struct {
+	Name             string         `koanf:"name"`
+	Token            string         `koanf:"token"`
+	ScanContext      []string       `koanf:"context"`
+	Areas            []string       `koanf:"areas"`
+	AreaNames        []geo.AreaName `koanf:"-"`
+	DowngradeContext string         `koanf:"downgrade_context"`
 }
 
 type scanRule struct {
@@ -129,6 +159,10 @@ type scanRule struct {
 	ProcessPokestops *bool          `koanf:"pokestops"`
 	ProcessGyms      *bool          `koanf:"gyms"`
 	ProcessStations  *bool          `koanf:"stations"`
+	MinLevel         *int           `koanf:"min_level"`
+	SocialMinLevel   *int           `koanf:"social_min_level"`
+	AllowMethods     []string       `koanf:"allow_methods"`
+	DenyMethods      []string       `koanf:"deny_methods"`
 }
 
 var Config configDefinition
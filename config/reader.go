@@ -43,12 +43,27 @@ func ReadConfig() (configDefinition, error) {
 			StatsDays:   7,
 			DeviceHours: 24,
 		},
+		Recorder: recorder{
+			Filename:   "recordings/raw.jsonl",
+			MaxSize:    100,
+			MaxBackups: 24,
+			Compress:   true,
+		},
 		Database: database{
 			MaxPool: 100,
 		},
 		Tuning: tuning{
 			MaxPokemonResults:  3000,
 			MaxPokemonDistance: 100,
+			MaxFortResults:     3000,
+			MaxSpawnpoints:     10000,
+			DecodeWorkers:      50,
+			DecodeQueueSize:    1000,
+			DeadLetterMax:      1000,
+			RawMaxBodySize:     5,
+			ClockSkew:          30,
+			DeviceSilentMins:   10,
+			EncounterLeaseSecs: 60,
 		},
 		Pvp: pvp{
 			LevelCaps: []int{50, 51},
@@ -73,6 +88,10 @@ func ReadConfig() (configDefinition, error) {
 		} else if strings.HasPrefix(key, "scan_rules") {
 			parseEnvVarToSlice("scan_rules", key, value, currentMap)
 
+			return "", nil
+		} else if strings.HasPrefix(key, "raw_credentials") {
+			parseEnvVarToSlice("raw_credentials", key, value, currentMap)
+
 			return "", nil
 		}
 
@@ -99,6 +118,14 @@ func ReadConfig() (configDefinition, error) {
 	for i := 0; i < len(Config.ScanRules); i++ {
 		rule := &Config.ScanRules[i]
 		rule.AreaNames = splitIntoAreaAndFenceName(rule.Areas)
+		rule.AllowMethods = normaliseMethodNames(rule.AllowMethods)
+		rule.DenyMethods = normaliseMethodNames(rule.DenyMethods)
+	}
+
+	// translate raw credential areas to array of geo.AreaName struct
+	for i := 0; i < len(Config.RawCredentials); i++ {
+		credential := &Config.RawCredentials[i]
+		credential.AreaNames = splitIntoAreaAndFenceName(credential.Areas)
 	}
 
 	return Config, nil
@@ -122,6 +149,12 @@ func parseEnvVarToSlice(sliceName string, key string, value string, currentMap m
 	currentMap[sliceName].([]interface{})[index].(map[string]interface{})[lastPart] = value
 }
 
+// AreaNamesFromStrings parses area names in the same form as the config, such as
+// "London/Chelsea", "London/*" or "Chelsea"
+func AreaNamesFromStrings(areaNames []string) []geo.AreaName {
+	return splitIntoAreaAndFenceName(areaNames)
+}
+
 func splitIntoAreaAndFenceName(areaNames []string) (areas []geo.AreaName) {
 	for _, areaName := range areaNames {
 		splitted := strings.Split(areaName, "/") // "London/*", "London/Chelsea", "Chelsea"
@@ -134,6 +167,15 @@ func splitIntoAreaAndFenceName(areaNames []string) (areas []geo.AreaName) {
 	return
 }
 
+// normaliseMethodNames allows methods to be given with or without the METHOD_ prefix, in
+// any case ("FORT_SEARCH", "method_fort_search")
+func normaliseMethodNames(methods []string) []string {
+	for i, method := range methods {
+		methods[i] = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(method)), "METHOD_")
+	}
+	return methods
+}
+
 func splitIntoHeaderMap(rawHeader []string) map[string]string {
 	headerMap := make(map[string]string)
 	for _, header := range rawHeader {
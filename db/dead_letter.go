@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// DeadLetter is a proto which could not be unmarshalled, kept so it can be inspected and
+// decoded again once the protos have been regenerated
+type DeadLetter struct {
+	Id          int64     `db:"id" json:"id"`
+	Method      int       `db:"method" json:"method"`
+	Error       string    `db:"error" json:"error"`
+	Uuid        string    `db:"uuid" json:"uuid"`
+	Account     string    `db:"account" json:"account"`
+	UserAgent   string    `db:"user_agent" json:"user_agent"`
+	ScanContext string    `db:"scan_context" json:"scan_context"`
+	Level       int       `db:"level" json:"level"`
+	Lat         float64   `db:"lat" json:"lat"`
+	Lon         float64   `db:"lon" json:"lon"`
+	HaveAr      null.Bool `db:"have_ar" json:"have_ar"`
+	Request     []byte    `db:"request" json:"request,omitempty"`
+	Payload     []byte    `db:"payload" json:"payload,omitempty"`
+	Received    int64     `db:"received" json:"received"`
+}
+
+const deadLetterSummaryColumns = "id, method, error, uuid, account, user_agent, scan_context, level, lat, lon, have_ar, received"
+
+// InsertDeadLetter stores a dead letter, removing the oldest entries so that no more than
+// maxEntries are kept
+func InsertDeadLetter(ctx context.Context, db DbDetails, deadLetter *DeadLetter, maxEntries int) error {
+	res, err := db.GeneralDb.NamedExecContext(ctx,
+		"INSERT INTO dead_letter (method, error, uuid, account, user_agent, scan_context, level, lat, lon, have_ar, request, payload, received) "+
+			"VALUES (:method, :error, :uuid, :account, :user_agent, :scan_context, :level, :lat, :lon, :have_ar, :request, :payload, :received)",
+		deadLetter)
+	statsCollector.IncDbQuery("insert dead_letter", err)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	deadLetter.Id = id
+
+	_, err = db.GeneralDb.ExecContext(ctx, "DELETE FROM dead_letter WHERE id <= ?", id-int64(maxEntries))
+	statsCollector.IncDbQuery("delete dead_letter", err)
+	return err
+}
+
+// GetDeadLetters returns dead letters without their payloads, newest first, optionally
+// filtered to a single method
+func GetDeadLetters(ctx context.Context, db DbDetails, method int, limit int) ([]DeadLetter, error) {
+	deadLetters := []DeadLetter{}
+	var err error
+	if method != 0 {
+		err = db.GeneralDb.SelectContext(ctx, &deadLetters, "SELECT "+deadLetterSummaryColumns+" FROM dead_letter "+
+			"WHERE method = ? ORDER BY id DESC LIMIT ?", method, limit)
+	} else {
+		err = db.GeneralDb.SelectContext(ctx, &deadLetters, "SELECT "+deadLetterSummaryColumns+" FROM dead_letter "+
+			"ORDER BY id DESC LIMIT ?", limit)
+	}
+	statsCollector.IncDbQuery("select dead_letter", err)
+	return deadLetters, err
+}
+
+// GetDeadLetterPayloads returns dead letters including their payloads, oldest first,
+// optionally filtered to a single method
+func GetDeadLetterPayloads(ctx context.Context, db DbDetails, method int, limit int) ([]DeadLetter, error) {
+	deadLetters := []DeadLetter{}
+	var err error
+	if method != 0 {
+		err = db.GeneralDb.SelectContext(ctx, &deadLetters, "SELECT * FROM dead_letter "+
+			"WHERE method = ? ORDER BY id LIMIT ?", method, limit)
+	} else {
+		err = db.GeneralDb.SelectContext(ctx, &deadLetters, "SELECT * FROM dead_letter "+
+			"ORDER BY id LIMIT ?", limit)
+	}
+	statsCollector.IncDbQuery("select dead_letter", err)
+	return deadLetters, err
+}
+
+// GetDeadLetter returns a single dead letter including its payload, or nil if not found
+func GetDeadLetter(ctx context.Context, db DbDetails, id int64) (*DeadLetter, error) {
+	deadLetters := []DeadLetter{}
+	err := db.GeneralDb.SelectContext(ctx, &deadLetters, "SELECT * FROM dead_letter WHERE id = ?", id)
+	statsCollector.IncDbQuery("select dead_letter", err)
+	if err != nil || len(deadLetters) == 0 {
+		return nil, err
+	}
+	return &deadLetters[0], nil
+}
+
+func DeleteDeadLetter(ctx context.Context, db DbDetails, id int64) error {
+	_, err := db.GeneralDb.ExecContext(ctx, "DELETE FROM dead_letter WHERE id = ?", id)
+	statsCollector.IncDbQuery("delete dead_letter", err)
+	return err
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+)
+
+type Device struct {
+	Id              string  `db:"id"`
+	Lat             float64 `db:"lat"`
+	Lon             float64 `db:"lon"`
+	ScanContext     string  `db:"scan_context"`
+	Account         string  `db:"account"`
+	Areas           string  `db:"areas"`
+	AssignedAreas   string  `db:"assigned_areas"`
+	LastMethod      string  `db:"last_method"`
+	ProtosPerMinute int     `db:"protos_per_minute"`
+	Silent          bool    `db:"silent"`
+	OutOfArea       bool    `db:"out_of_area"`
+	FirstSeen       int64   `db:"first_seen"`
+	LastUpdate      int64   `db:"last_update"`
+}
+
+// LoadDevices returns every device updated since the given time
+func LoadDevices(ctx context.Context, db DbDetails, since int64) ([]Device, error) {
+	devices := []Device{}
+	err := db.GeneralDb.SelectContext(ctx, &devices, "SELECT * FROM device WHERE last_update >= ?", since)
+	statsCollector.IncDbQuery("select device", err)
+	return devices, err
+}
+
+// SaveDevices writes a batch of devices, inserting those not yet stored
+func SaveDevices(ctx context.Context, db DbDetails, devices []Device) error {
+	if len(devices) == 0 {
+		return nil
+	}
+	_, err := db.GeneralDb.NamedExecContext(ctx,
+		"INSERT INTO device (id, lat, lon, scan_context, account, areas, assigned_areas, last_method, "+
+			"protos_per_minute, silent, out_of_area, first_seen, last_update) "+
+			"VALUES (:id, :lat, :lon, :scan_context, :account, :areas, :assigned_areas, :last_method, "+
+			":protos_per_minute, :silent, :out_of_area, :first_seen, :last_update) "+
+			"ON DUPLICATE KEY UPDATE lat = VALUES(lat), lon = VALUES(lon), scan_context = VALUES(scan_context), "+
+			"account = VALUES(account), areas = VALUES(areas), assigned_areas = VALUES(assigned_areas), "+
+			"last_method = VALUES(last_method), protos_per_minute = VALUES(protos_per_minute), "+
+			"silent = VALUES(silent), out_of_area = VALUES(out_of_area), last_update = VALUES(last_update)",
+		devices)
+	statsCollector.IncDbQuery("insert device", err)
+	return err
+}
+
+// DeleteDevicesBefore removes devices not updated since the given time
+func DeleteDevicesBefore(ctx context.Context, db DbDetails, before int64) error {
+	_, err := db.GeneralDb.ExecContext(ctx, "DELETE FROM device WHERE last_update < ?", before)
+	statsCollector.IncDbQuery("delete device", err)
+	return err
+}
@@ -37,3 +37,16 @@ func ClearOldGyms(ctx context.Context, db DbDetails, gymIds []string) error {
 	}
 	return nil
 }
+
+// ClearGymDefenders removes the stored defenders of the given gyms
+func ClearGymDefenders(ctx context.Context, db DbDetails, gymIds []string) error {
+	query, args, _ := sqlx.In("DELETE FROM gym_defender WHERE gym_id IN (?);", gymIds)
+	query = db.GeneralDb.Rebind(query)
+
+	_, err := db.GeneralDb.ExecContext(ctx, query, args...)
+	statsCollector.IncDbQuery("clear gym-defenders", err)
+	if err != nil {
+		return err
+	}
+	return nil
+}
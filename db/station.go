@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func FindOldStations(ctx context.Context, db DbDetails, cellId int64) ([]string, error) {
+	fortIds := []FortId{}
+	err := db.GeneralDb.SelectContext(ctx, &fortIds,
+		"SELECT id FROM station WHERE is_inactive = 0 AND cell_id = ? AND updated < UNIX_TIMESTAMP() - 3600;", cellId)
+	statsCollector.IncDbQuery("select old-stations", err)
+	if err != nil {
+		return nil, err
+	}
+	if len(fortIds) == 0 {
+		return nil, nil
+	}
+
+	// convert slices of struct to slices of string
+	var list []string
+	for _, element := range fortIds {
+		list = append(list, element.Id)
+	}
+	return list, nil
+}
+
+func ClearOldStations(ctx context.Context, db DbDetails, stationIds []string) error {
+	query, args, _ := sqlx.In("UPDATE station SET is_inactive = 1 WHERE id IN (?);", stationIds)
+	query = db.GeneralDb.Rebind(query)
+
+	_, err := db.GeneralDb.ExecContext(ctx, query, args...)
+	statsCollector.IncDbQuery("clear old-stations", err)
+	if err != nil {
+		return err
+	}
+	return nil
+}
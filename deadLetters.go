@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"golbat/config"
+	db2 "golbat/db"
+)
+
+const deadLetterListLimit = 1000
+
+// storeDeadLetter keeps a proto which could not be unmarshalled so it can be inspected, or
+// decoded again once the protos have been updated
+func storeDeadLetter(protoData *ProtoData, errorText string, received time.Time) {
+	maxEntries := config.Config.Tuning.DeadLetterMax
+	if maxEntries <= 0 {
+		return
+	}
+
+	deadLetter := db2.DeadLetter{
+		Method:      protoData.Method,
+		Error:       truncateString(errorText, 512),
+		Uuid:        truncateString(protoData.Uuid, 100),
+		Account:     truncateString(protoData.Account, 100),
+		UserAgent:   truncateString(protoData.UserAgent, 255),
+		ScanContext: truncateString(protoData.ScanContext, 100),
+		Level:       protoData.Level,
+		Lat:         protoData.Lat,
+		Lon:         protoData.Lon,
+		HaveAr:      null.BoolFromPtr(protoData.HaveAr),
+		Request:     protoData.Request,
+		Payload:     protoData.Data,
+		Received:    received.Unix(),
+	}
+	if deadLetter.Payload == nil {
+		deadLetter.Payload = []byte{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db2.InsertDeadLetter(ctx, dbDetails, &deadLetter, maxEntries); err != nil {
+		log.Errorf("Failed to store dead letter for %s: %s", getMethodName(protoData.Method, true), err)
+	}
+}
+
+func truncateString(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
+
+func (d *DeadLetterEntry) protoData() ProtoData {
+	return ProtoData{
+		Method:      d.Method,
+		Data:        d.Payload,
+		Request:     d.Request,
+		HaveAr:      d.HaveAr.Ptr(),
+		Account:     d.Account,
+		Level:       d.Level,
+		Uuid:        d.Uuid,
+		ScanContext: d.ScanContext,
+		Lat:         d.Lat,
+		Lon:         d.Lon,
+		UserAgent:   d.UserAgent,
+		Timestamp:   d.Received * 1000,
+	}
+}
+
+// DeadLetterEntry is a dead letter as returned by the api, with the method name resolved
+type DeadLetterEntry struct {
+	db2.DeadLetter
+	MethodName string `json:"method_name"`
+}
+
+type DeadLetterRetryResult struct {
+	Id int64 `json:"id"`
+	DecodeResult
+}
+
+// deadLetterMethodParam reads an optional ?method= filter
+func deadLetterMethodParam(c *gin.Context) (int, error) {
+	method := c.Query("method")
+	if method == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(method)
+}
+
+func deadLetterLimitParam(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 || limit > deadLetterListLimit {
+		return deadLetterListLimit
+	}
+	return limit
+}
+
+func GetDeadLetters(c *gin.Context) {
+	method, err := deadLetterMethodParam(c)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	deadLetters, err := db2.GetDeadLetters(ctx, dbDetails, method, deadLetterLimitParam(c))
+	cancel()
+	if err != nil {
+		log.Warnf("GET /api/debug/dead-letters Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	entries := make([]DeadLetterEntry, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		entries = append(entries, DeadLetterEntry{
+			DeadLetter: deadLetter,
+			MethodName: getMethodName(deadLetter.Method, false),
+		})
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetDeadLetter returns a single dead letter including its request and payload, base64 encoded
+func GetDeadLetter(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	deadLetter, err := db2.GetDeadLetter(ctx, dbDetails, id)
+	cancel()
+	if err != nil {
+		log.Warnf("GET /api/debug/dead-letters/id/:id Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if deadLetter == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeadLetterEntry{
+		DeadLetter: *deadLetter,
+		MethodName: getMethodName(deadLetter.Method, false),
+	})
+}
+
+// DownloadDeadLetters returns the dead letters as a recording, one proto per line, which can
+// be fed back through `golbat replay`
+func DownloadDeadLetters(c *gin.Context) {
+	method, err := deadLetterMethodParam(c)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	deadLetters, err := db2.GetDeadLetterPayloads(ctx, dbDetails, method, deadLetterLimitParam(c))
+	cancel()
+	if err != nil {
+		log.Warnf("GET /api/debug/dead-letters/download Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=dead-letters-%d.jsonl", time.Now().Unix()))
+	c.Status(http.StatusOK)
+	c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(c.Writer)
+	for _, deadLetter := range deadLetters {
+		_ = encoder.Encode(RecordedProto{
+			Received:    deadLetter.Received * 1000,
+			Method:      deadLetter.Method,
+			Request:     deadLetter.Request,
+			Response:    deadLetter.Payload,
+			Account:     deadLetter.Account,
+			Level:       deadLetter.Level,
+			Uuid:        deadLetter.Uuid,
+			ScanContext: deadLetter.ScanContext,
+			Lat:         deadLetter.Lat,
+			Lon:         deadLetter.Lon,
+			HaveAr:      deadLetter.HaveAr.Ptr(),
+			UserAgent:   deadLetter.UserAgent,
+			Timestamp:   deadLetter.Received * 1000,
+		})
+	}
+}
+
+// RetryDeadLetter decodes a single dead letter again, removing it if it now decodes
+func RetryDeadLetter(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	deadLetter, err := db2.GetDeadLetter(ctx, dbDetails, id)
+	cancel()
+	if err != nil {
+		log.Warnf("POST /api/debug/dead-letters/id/:id/retry Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if deadLetter == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, retryDeadLetters([]db2.DeadLetter{*deadLetter})[0])
+}
+
+// RetryDeadLetters decodes every dead letter (optionally for a single method) again,
+// removing those which now decode
+func RetryDeadLetters(c *gin.Context) {
+	method, err := deadLetterMethodParam(c)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	deadLetters, err := db2.GetDeadLetterPayloads(ctx, dbDetails, method, deadLetterLimitParam(c))
+	cancel()
+	if err != nil {
+		log.Warnf("POST /api/debug/dead-letters/retry Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, retryDeadLetters(deadLetters))
+}
+
+// retryDeadLetters decodes directly rather than through the decode queue, so a proto which
+// still fails is reported rather than stored again. The scan rule and duplicate checks and
+// account and device tracking were all made when the proto was first received, so are not
+// repeated, and data is saved as at the time it was received, however old. It still will
+// not overwrite anything fresher. Only a dead letter which now decodes and is processed is
+// removed.
+func retryDeadLetters(deadLetters []db2.DeadLetter) []DeadLetterRetryResult {
+	timeout := 5 * time.Second
+	if config.Config.Tuning.ExtendedTimeout {
+		timeout = 30 * time.Second
+	}
+
+	results := make([]DeadLetterRetryResult, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		entry := DeadLetterEntry{DeadLetter: deadLetter}
+		protoData := entry.protoData()
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		result := decodeAsAt(ctx, protoData.Method, &protoData, getScanParameters(&protoData), false)
+		cancel()
+
+		if result.Status == DecodeStatusProcessed {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := db2.DeleteDeadLetter(ctx, dbDetails, deadLetter.Id); err != nil {
+				log.Warnf("Failed to remove dead letter %d: %s", deadLetter.Id, err)
+			}
+			cancel()
+		}
+		results = append(results, DeadLetterRetryResult{Id: deadLetter.Id, DecodeResult: result})
+	}
+	return results
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+)
+
+var errDecodeQueueFull = errors.New("decode queue full")
+var errDecodeQueueClosed = errors.New("decode queue closed")
+
+// decodeBatch is a set of protos received in a single raw submission. Protos in a batch
+// are decoded in sequence by a single worker, as the original goroutine-per-request did.
+type decodeBatch struct {
+	protoData []ProtoData
+	queuedAt  time.Time
+	results   chan []DecodeResult // optional, receives the results once decoded
+}
+
+// decodeQueue is a bounded pool of decode workers shared by the /raw endpoint and the
+// grpc receiver. When the queue is full new batches are refused rather than spawning
+// unbounded goroutines, so senders can back off.
+type decodeQueue struct {
+	batches chan decodeBatch
+	workers int
+
+	closeMutex sync.RWMutex
+	closed     bool
+	done       chan struct{} // closed once every worker has exited
+}
+
+var rawDecodeQueue *decodeQueue
+
+func newDecodeQueue(workers, queueSize int) *decodeQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	return &decodeQueue{
+		batches: make(chan decodeBatch, queueSize),
+		workers: workers,
+		done:    make(chan struct{}),
+	}
+}
+
+// StartDecodeQueue creates the shared decode queue and starts its workers. When ctx is
+// cancelled the queue stops accepting batches and workers exit once it is drained; wg is
+// used to allow shutdown to wait for the remaining decodes.
+func StartDecodeQueue(ctx context.Context, wg *sync.WaitGroup) {
+	tuning := config.Config.Tuning
+	rawDecodeQueue = newDecodeQueue(tuning.DecodeWorkers, tuning.DecodeQueueSize)
+	log.Infof("Starting %d decode workers with a queue of %d", rawDecodeQueue.workers, cap(rawDecodeQueue.batches))
+
+	var workerWg sync.WaitGroup
+	for i := 0; i < rawDecodeQueue.workers; i++ {
+		wg.Add(1)
+		workerWg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer workerWg.Done()
+			rawDecodeQueue.run(ctx)
+		}()
+	}
+	go func() {
+		workerWg.Wait()
+		close(rawDecodeQueue.done)
+	}()
+}
+
+// Done returns a channel closed once every worker has exited, after which no further
+// results will be delivered
+func (q *decodeQueue) Done() <-chan struct{} {
+	return q.done
+}
+
+// Enqueue adds a batch of protos to the queue without blocking. errDecodeQueueFull is
+// returned when there is no space and errDecodeQueueClosed once shutdown has started,
+// source is used only to label the drop metric.
+func (q *decodeQueue) Enqueue(source string, protoData []ProtoData) error {
+	return q.enqueue(source, protoData, nil)
+}
+
+// EnqueueAndWait adds a batch of protos to the queue without blocking, returning a
+// channel that receives the per-proto results once a worker has decoded the batch
+func (q *decodeQueue) EnqueueAndWait(source string, protoData []ProtoData) (<-chan []DecodeResult, error) {
+	results := make(chan []DecodeResult, 1)
+	if len(protoData) == 0 {
+		results <- []DecodeResult{}
+		return results, nil
+	}
+	if err := q.enqueue(source, protoData, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (q *decodeQueue) enqueue(source string, protoData []ProtoData, results chan []DecodeResult) error {
+	if len(protoData) == 0 {
+		return nil
+	}
+
+	q.closeMutex.RLock()
+	defer q.closeMutex.RUnlock()
+	if q.closed {
+		return errDecodeQueueClosed
+	}
+
+	now := time.Now()
+	select {
+	case q.batches <- decodeBatch{protoData: protoData, queuedAt: now, results: results}:
+		statsCollector.SetDecodeQueueDepth(float64(len(q.batches)))
+		protoRecorder.Record(protoData, now)
+		return nil
+	default:
+		statsCollector.IncDecodeQueueDrops(source)
+		return errDecodeQueueFull
+	}
+}
+
+// close stops the queue accepting batches. Once it returns no enqueue is in progress, so
+// the batches left in the queue are all that remain to be decoded.
+func (q *decodeQueue) close() {
+	q.closeMutex.Lock()
+	q.closed = true
+	q.closeMutex.Unlock()
+}
+
+func (q *decodeQueue) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			q.close()
+			q.drain()
+			return
+		case batch := <-q.batches:
+			q.decode(batch)
+		}
+	}
+}
+
+// drain decodes the batches still queued at shutdown
+func (q *decodeQueue) drain() {
+	for {
+		select {
+		case batch := <-q.batches:
+			q.decode(batch)
+		default:
+			return
+		}
+	}
+}
+
+func (q *decodeQueue) decode(batch decodeBatch) {
+	statsCollector.SetDecodeQueueDepth(float64(len(q.batches)))
+	statsCollector.UpdateDecodeQueueWait(time.Since(batch.queuedAt).Seconds())
+	results := decodeProtoBatch(batch.protoData, batch.queuedAt, true)
+	if batch.results != nil {
+		batch.results <- results
+	}
+}
+
+// decodeProtoBatch decodes each proto in sequence with its own timeout. A live batch has
+// just been received: protos older than the max age are dropped, and those which could not
+// be parsed are kept as dead letters, as at received; other errors would only fail again on
+// retry. A replayed batch is expected to be old and is still held in its recording.
+func decodeProtoBatch(protoData []ProtoData, received time.Time, live bool) []DecodeResult {
+	timeout := 5 * time.Second
+	if config.Config.Tuning.ExtendedTimeout {
+		timeout = 30 * time.Second
+	}
+
+	results := make([]DecodeResult, 0, len(protoData))
+	for i := range protoData {
+		entry := &protoData[i]
+		// provide independent cancellation contexts for each proto decode
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		result := decode(ctx, entry.Method, entry, live)
+		cancel()
+		if live && result.parseFailed {
+			storeDeadLetter(entry, result.Result, received)
+		}
+		results = append(results, result)
+	}
+	return results
+}
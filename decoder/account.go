@@ -0,0 +1,241 @@
+package decoder
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+	log "github.com/sirupsen/logrus"
+
+	"golbat/pogo"
+	"golbat/webhooks"
+)
+
+// emptyGmoThreshold is the number of consecutive successful but empty GMOs after which an
+// account is assumed to be blinded
+const emptyGmoThreshold = 5
+
+// Account tracks the health of a scanning account, keyed on the account name sent with
+// each proto. Accounts are kept in memory only.
+type Account struct {
+	Username    string
+	Level       int
+	Device      string
+	Lat         float64
+	Lon         float64
+	FirstSeen   int64
+	LastSeen    int64
+	Protos      map[string]int64
+	NonSuccess  map[string]int64
+	Warned      bool
+	WarnExpiry  int64
+	Banned      bool
+	Suspended   bool
+	EmptyGmos   int
+	FlagReasons []string
+}
+
+type ApiAccount struct {
+	Username       string             `json:"username"`
+	Level          int                `json:"level"`
+	Device         string             `json:"device"`
+	FirstSeen      int64              `json:"first_seen"`
+	LastSeen       int64              `json:"last_seen"`
+	Protos         map[string]int64   `json:"protos"`
+	NonSuccessRate map[string]float64 `json:"non_success_rate"`
+	Warned         bool               `json:"warned"`
+	WarnExpiry     int64              `json:"warn_expiry"`
+	Banned         bool               `json:"banned"`
+	Suspended      bool               `json:"suspended"`
+	EmptyGmos      int                `json:"consecutive_empty_gmos"`
+	Flagged        bool               `json:"flagged"`
+	FlagReasons    []string           `json:"flag_reasons"`
+}
+
+var accountCache *ttlcache.Cache[string, Account]
+
+// updateAccount applies a change to an account under its lock, creating the account if it
+// has not been seen before
+func updateAccount(username string, update func(account *Account)) {
+	if username == "" {
+		return
+	}
+
+	accountMutex, _ := accountStripedMutex.GetLock(username)
+	accountMutex.Lock()
+	defer accountMutex.Unlock()
+
+	var account Account
+	if item := accountCache.Get(username); item != nil {
+		account = item.Value()
+	} else {
+		account = Account{
+			Username:   username,
+			FirstSeen:  time.Now().Unix(),
+			Protos:     map[string]int64{},
+			NonSuccess: map[string]int64{},
+		}
+	}
+	oldReasons := account.FlagReasons
+
+	update(&account)
+
+	account.FlagReasons = account.flagReasons()
+	accountCache.Set(username, account, ttlcache.DefaultTTL)
+
+	// webhook whenever an account picks up a new reason to be flagged
+	for _, reason := range account.FlagReasons {
+		if !slices.Contains(oldReasons, reason) {
+			log.Infof("Account %s flagged: %v", username, account.FlagReasons)
+			createAccountWebhook(&account)
+			break
+		}
+	}
+}
+
+func (account *Account) flagReasons() []string {
+	var reasons []string
+	if account.Banned {
+		reasons = append(reasons, "banned")
+	}
+	if account.Suspended {
+		reasons = append(reasons, "suspended")
+	}
+	if account.Warned {
+		reasons = append(reasons, "warned")
+	}
+	if account.EmptyGmos >= emptyGmoThreshold {
+		reasons = append(reasons, "empty_gmo")
+	}
+	return reasons
+}
+
+func (account *Account) flagged() bool {
+	return len(account.FlagReasons) > 0
+}
+
+// RecordAccountProto counts a proto received from an account
+func RecordAccountProto(username string, device string, level int, lat, lon float64, method string) {
+	updateAccount(username, func(account *Account) {
+		account.Level = level
+		account.Device = device
+		if lat != 0 || lon != 0 {
+			account.Lat, account.Lon = lat, lon
+		}
+		account.LastSeen = time.Now().Unix()
+		account.Protos[method]++
+	})
+}
+
+// RecordAccountNonSuccess counts a non-success response received by an account
+func RecordAccountNonSuccess(username string, method string) {
+	updateAccount(username, func(account *Account) {
+		account.NonSuccess[method]++
+	})
+}
+
+// RecordAccountGmo tracks successful GMOs which contained nothing at all, which is how a
+// blinded account appears
+func RecordAccountGmo(username string, empty bool) {
+	updateAccount(username, func(account *Account) {
+		if empty {
+			account.EmptyGmos++
+		} else {
+			account.EmptyGmos = 0
+		}
+	})
+}
+
+// UpdateAccountWithGetPlayer records the warning, suspension and ban flags from a GET_PLAYER
+func UpdateAccountWithGetPlayer(username string, player *pogo.GetPlayerOutProto) string {
+	if username == "" {
+		return "No account"
+	}
+	updateAccount(username, func(account *Account) {
+		account.Warned = player.Warn
+		account.WarnExpiry = player.WarnExpireMs / 1000
+		account.Banned = player.Banned
+		account.Suspended = player.WasSuspended
+	})
+	return "Account " + username
+}
+
+func createAccountWebhook(account *Account) {
+	accountHook := map[string]interface{}{
+		"username":               account.Username,
+		"device":                 account.Device,
+		"level":                  account.Level,
+		"warned":                 account.Warned,
+		"warn_expiry":            account.WarnExpiry,
+		"banned":                 account.Banned,
+		"suspended":              account.Suspended,
+		"consecutive_empty_gmos": account.EmptyGmos,
+		"flag_reasons":           account.FlagReasons,
+		"last_seen":              account.LastSeen,
+	}
+	areas := MatchStatsGeofence(account.Lat, account.Lon)
+	webhooksSender.AddMessage(webhooks.Account, accountHook, areas)
+}
+
+func (account *Account) toApi() ApiAccount {
+	apiAccount := ApiAccount{
+		Username:       account.Username,
+		Level:          account.Level,
+		Device:         account.Device,
+		FirstSeen:      account.FirstSeen,
+		LastSeen:       account.LastSeen,
+		Protos:         make(map[string]int64, len(account.Protos)),
+		NonSuccessRate: make(map[string]float64, len(account.NonSuccess)),
+		Warned:         account.Warned,
+		WarnExpiry:     account.WarnExpiry,
+		Banned:         account.Banned,
+		Suspended:      account.Suspended,
+		EmptyGmos:      account.EmptyGmos,
+		Flagged:        account.flagged(),
+		FlagReasons:    append([]string{}, account.FlagReasons...),
+	}
+	for method, count := range account.Protos {
+		apiAccount.Protos[method] = count
+	}
+	for method, count := range account.NonSuccess {
+		if total := account.Protos[method]; total > 0 {
+			apiAccount.NonSuccessRate[method] = float64(count) / float64(total)
+		}
+	}
+	return apiAccount
+}
+
+// GetAccounts returns all accounts seen recently, optionally only those which are flagged
+func GetAccounts(flaggedOnly bool) []ApiAccount {
+	accounts := []ApiAccount{}
+	for _, item := range accountCache.Items() {
+		username := item.Key()
+		accountMutex, _ := accountStripedMutex.GetLock(username)
+		accountMutex.Lock()
+		account := item.Value()
+		if !flaggedOnly || account.flagged() {
+			accounts = append(accounts, account.toApi())
+		}
+		accountMutex.Unlock()
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Username < accounts[j].Username
+	})
+	return accounts
+}
+
+// GetAccount returns a single account, or nil if it has not been seen recently
+func GetAccount(username string) *ApiAccount {
+	accountMutex, _ := accountStripedMutex.GetLock(username)
+	accountMutex.Lock()
+	defer accountMutex.Unlock()
+
+	item := accountCache.Get(username)
+	if item == nil {
+		return nil
+	}
+	account := item.Value()
+	apiAccount := account.toApi()
+	return &apiAccount
+}
@@ -0,0 +1,209 @@
+package decoder
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+	pb "golbat/grpc"
+)
+
+type ApiGymScan struct {
+	Min        geo.Location      `json:"min"`
+	Max        geo.Location      `json:"max"`
+	Fence      []geo.ApiLocation `json:"fence"`
+	Limit      int               `json:"limit"`
+	DnfFilters []ApiGymDnfFilter `json:"filters"`
+}
+
+// ApiGymDnfFilter matches a gym when every given condition holds. Raid conditions only
+// match gyms with a raid (or egg) which has not yet ended.
+type ApiGymDnfFilter struct {
+	Team           []int8                `json:"team"`
+	AvailableSlots *ApiPokemonDnfMinMax8 `json:"available_slots"`
+	RaidLevel      *ApiPokemonDnfMinMax8 `json:"raid_level"`
+	RaidPokemon    []ApiPokemonDnfId     `json:"raid_pokemon"`
+	ExRaidEligible *bool                 `json:"ex_raid_eligible"`
+	InBattle       *bool                 `json:"in_battle"`
+	PowerUpLevel   *ApiPokemonDnfMinMax8 `json:"power_up_level"`
+}
+
+func isGymDnfMatch(fortLookup *FortLookup, filter *ApiGymDnfFilter, now int64) bool {
+	if len(filter.Team) > 0 && !slices.Contains(filter.Team, fortLookup.TeamId) ||
+		filter.AvailableSlots != nil && (fortLookup.AvailableSlots < filter.AvailableSlots.Min || fortLookup.AvailableSlots > filter.AvailableSlots.Max) ||
+		filter.ExRaidEligible != nil && fortLookup.ExRaidEligible != *filter.ExRaidEligible ||
+		filter.InBattle != nil && fortLookup.InBattle != *filter.InBattle ||
+		filter.PowerUpLevel != nil && (fortLookup.PowerUpLevel < filter.PowerUpLevel.Min || fortLookup.PowerUpLevel > filter.PowerUpLevel.Max) {
+		return false
+	}
+
+	if filter.RaidLevel == nil && len(filter.RaidPokemon) == 0 {
+		return true
+	}
+	if fortLookup.RaidEndTimestamp <= now ||
+		filter.RaidLevel != nil && (fortLookup.RaidLevel < filter.RaidLevel.Min || fortLookup.RaidLevel > filter.RaidLevel.Max) {
+		return false
+	}
+	if len(filter.RaidPokemon) == 0 {
+		return true
+	}
+	for _, raidPokemon := range filter.RaidPokemon {
+		if raidPokemon.Pokemon == fortLookup.RaidPokemonId &&
+			(raidPokemon.Form == nil || *raidPokemon.Form == fortLookup.RaidPokemonForm) {
+			return true
+		}
+	}
+	return false
+}
+
+func internalGetGymInArea(retrieveParameters ApiGymScan) []string {
+	maxGyms := config.Config.Tuning.MaxFortResults
+	if retrieveParameters.Limit > 0 && retrieveParameters.Limit < maxGyms {
+		maxGyms = retrieveParameters.Limit
+	}
+
+	return scanFortTree("GetGymInArea", retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence, maxGyms,
+		func(fortId string, fortLookup *FortLookup, now int64, add func(string)) {
+			if !fortLookup.IsGym {
+				return
+			}
+			matched := len(retrieveParameters.DnfFilters) == 0
+			for x := 0; x < len(retrieveParameters.DnfFilters) && !matched; x++ {
+				matched = isGymDnfMatch(fortLookup, &retrieveParameters.DnfFilters[x], now)
+			}
+			if matched {
+				add(fortId)
+			}
+		})
+}
+
+// GetGymInArea returns the gyms within the bounding box matching any of the filters. Gyms
+// are returned from the cache, or reloaded if they have since been evicted.
+func GetGymInArea(ctx context.Context, db db.DbDetails, retrieveParameters ApiGymScan) []*Gym {
+	returnKeys := internalGetGymInArea(retrieveParameters)
+	results := make([]*Gym, 0, len(returnKeys))
+
+	for _, key := range returnKeys {
+		gym, err := getGymRecord(ctx, db, key)
+		if err != nil {
+			log.Errorf("GetGymInArea - unable to load gym %s: %s", key, err)
+			continue
+		}
+		if gym != nil && !gym.Deleted {
+			results = append(results, gym)
+		}
+	}
+	return results
+}
+
+func grpcRangeToMinMax8(minmax *pb.RangeMinMax) *ApiPokemonDnfMinMax8 {
+	if minmax == nil {
+		return nil
+	}
+	var minV int8 = 0
+	var maxV int8 = math.MaxInt8
+	if minmax.Min != nil {
+		minV = int8(*minmax.Min)
+	}
+	if minmax.Max != nil {
+		maxV = int8(*minmax.Max)
+	}
+	return &ApiPokemonDnfMinMax8{
+		Min: minV,
+		Max: maxV,
+	}
+}
+
+func grpcPokemonIdsToDnf(pokemonIds []*pb.PokemonId) []ApiPokemonDnfId {
+	var result []ApiPokemonDnfId
+	for _, pokemon := range pokemonIds {
+		dnfId := ApiPokemonDnfId{Pokemon: int16(pokemon.GetId())}
+		if pokemon.Form != nil {
+			form := int16(*pokemon.Form)
+			dnfId.Form = &form
+		}
+		result = append(result, dnfId)
+	}
+	return result
+}
+
+func GrpcGetGymInArea(ctx context.Context, db db.DbDetails, retrieveParameters *pb.GymScanRequest) []*pb.GymDetails {
+	apiRequest := ApiGymScan{
+		Min: geo.Location{
+			Latitude:  float64(retrieveParameters.MinLat),
+			Longitude: float64(retrieveParameters.MinLon),
+		},
+		Max: geo.Location{
+			Latitude:  float64(retrieveParameters.MaxLat),
+			Longitude: float64(retrieveParameters.MaxLon),
+		},
+		Limit: int(retrieveParameters.Limit),
+	}
+
+	for _, filter := range retrieveParameters.Filters {
+		var teams []int8
+		for _, team := range filter.Team {
+			teams = append(teams, int8(team))
+		}
+		apiRequest.DnfFilters = append(apiRequest.DnfFilters, ApiGymDnfFilter{
+			Team:           teams,
+			AvailableSlots: grpcRangeToMinMax8(filter.AvailableSlots),
+			RaidLevel:      grpcRangeToMinMax8(filter.RaidLevel),
+			RaidPokemon:    grpcPokemonIdsToDnf(filter.RaidPokemon),
+			ExRaidEligible: filter.ExRaidEligible,
+			InBattle:       filter.InBattle,
+			PowerUpLevel:   grpcRangeToMinMax8(filter.PowerUpLevel),
+		})
+	}
+
+	gyms := GetGymInArea(ctx, db, apiRequest)
+	results := make([]*pb.GymDetails, 0, len(gyms))
+
+	int32Ptr := func(value interface{ Ptr() *int64 }) *int32 {
+		if v := value.Ptr(); v != nil {
+			i := int32(*v)
+			return &i
+		}
+		return nil
+	}
+
+	for _, gym := range gyms {
+		results = append(results, &pb.GymDetails{
+			Id:                   gym.Id,
+			Lat:                  gym.Lat,
+			Lon:                  gym.Lon,
+			Name:                 gym.Name.Ptr(),
+			Url:                  gym.Url.Ptr(),
+			TeamId:               int32Ptr(gym.TeamId),
+			AvailableSlots:       int32Ptr(gym.AvailableSlots),
+			GuardingPokemonId:    int32Ptr(gym.GuardingPokemonId),
+			TotalCp:              int32Ptr(gym.TotalCp),
+			InBattle:             gym.InBattle.ValueOrZero() != 0,
+			ExRaidEligible:       gym.ExRaidEligible.ValueOrZero() != 0,
+			ArScanEligible:       gym.ArScanEligible.ValueOrZero() != 0,
+			PowerUpLevel:         int32Ptr(gym.PowerUpLevel),
+			PowerUpPoints:        int32Ptr(gym.PowerUpPoints),
+			PowerUpEndTimestamp:  gym.PowerUpEndTimestamp.Ptr(),
+			RaidLevel:            int32Ptr(gym.RaidLevel),
+			RaidSpawnTimestamp:   gym.RaidSpawnTimestamp.Ptr(),
+			RaidBattleTimestamp:  gym.RaidBattleTimestamp.Ptr(),
+			RaidEndTimestamp:     gym.RaidEndTimestamp.Ptr(),
+			RaidPokemonId:        int32Ptr(gym.RaidPokemonId),
+			RaidPokemonForm:      int32Ptr(gym.RaidPokemonForm),
+			RaidPokemonCostume:   int32Ptr(gym.RaidPokemonCostume),
+			RaidPokemonGender:    int32Ptr(gym.RaidPokemonGender),
+			RaidPokemonAlignment: int32Ptr(gym.RaidPokemonAlignment),
+			RaidPokemonCp:        int32Ptr(gym.RaidPokemonCp),
+			RaidPokemonMove_1:    int32Ptr(gym.RaidPokemonMove1),
+			RaidPokemonMove_2:    int32Ptr(gym.RaidPokemonMove2),
+			RaidIsExclusive:      gym.RaidIsExclusive.ValueOrZero() != 0,
+			Updated:              gym.Updated,
+		})
+	}
+	return results
+}
@@ -0,0 +1,159 @@
+package decoder
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+)
+
+// ApiIncidentScan searches for active incidents within the bounding box given by min and
+// max, or within fence if given. With summary set, counts per area and character are
+// returned instead of the incidents.
+type ApiIncidentScan struct {
+	Min        geo.Location           `json:"min"`
+	Max        geo.Location           `json:"max"`
+	Fence      []geo.ApiLocation      `json:"fence"`
+	Limit      int                    `json:"limit"`
+	DnfFilters []ApiIncidentDnfFilter `json:"filters"`
+	Summary    bool                   `json:"summary"`
+}
+
+// ApiIncidentDnfFilter matches an incident when every given condition holds. Where a
+// condition is a list, any entry in the list may match.
+type ApiIncidentDnfFilter struct {
+	Character     []int16 `json:"character"`
+	DisplayType   []int16 `json:"display_type"`
+	Confirmed     *bool   `json:"confirmed"`
+	LineupPokemon []int16 `json:"lineup_pokemon"`
+}
+
+// ApiIncidentResult is an incident along with the pokestop it is at
+type ApiIncidentResult struct {
+	*Incident
+	PokestopName string  `json:"pokestop_name"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+}
+
+type ApiIncidentSummary struct {
+	Area      string `json:"area"`
+	Character int16  `json:"character"`
+	Count     int    `json:"count"`
+}
+
+type incidentScanKey struct {
+	pokestopId string
+	incidentId string
+	character  int16
+}
+
+func isIncidentDnfMatch(incident *FortLookupIncident, filter *ApiIncidentDnfFilter) bool {
+	if len(filter.Character) > 0 && !slices.Contains(filter.Character, incident.Character) ||
+		len(filter.DisplayType) > 0 && !slices.Contains(filter.DisplayType, incident.DisplayType) ||
+		filter.Confirmed != nil && incident.Confirmed != *filter.Confirmed {
+		return false
+	}
+	if len(filter.LineupPokemon) > 0 && !slices.ContainsFunc(incident.Lineup[:], func(pokemonId int16) bool {
+		return pokemonId != 0 && slices.Contains(filter.LineupPokemon, pokemonId)
+	}) {
+		return false
+	}
+	return true
+}
+
+func internalGetIncidentsInArea(retrieveParameters ApiIncidentScan, maxIncidents int) []incidentScanKey {
+	return scanFortTree("GetIncidentsInArea", retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence, maxIncidents,
+		func(fortId string, fortLookup *FortLookup, now int64, add func(incidentScanKey)) {
+			if fortLookup.IsGym {
+				return
+			}
+			for i := range fortLookup.Incidents {
+				incident := &fortLookup.Incidents[i]
+				if incident.Expiration <= now {
+					continue
+				}
+				matched := len(retrieveParameters.DnfFilters) == 0
+				for x := 0; x < len(retrieveParameters.DnfFilters) && !matched; x++ {
+					matched = isIncidentDnfMatch(incident, &retrieveParameters.DnfFilters[x])
+				}
+				if matched {
+					add(incidentScanKey{pokestopId: fortId, incidentId: incident.Id, character: incident.Character})
+				}
+			}
+		})
+}
+
+// GetIncidentsInArea returns the active incidents within the area matching any of the filters
+func GetIncidentsInArea(ctx context.Context, db db.DbDetails, retrieveParameters ApiIncidentScan) []*ApiIncidentResult {
+	maxIncidents := config.Config.Tuning.MaxFortResults
+	if retrieveParameters.Limit > 0 && retrieveParameters.Limit < maxIncidents {
+		maxIncidents = retrieveParameters.Limit
+	}
+
+	returnKeys := internalGetIncidentsInArea(retrieveParameters, maxIncidents)
+	results := make([]*ApiIncidentResult, 0, len(returnKeys))
+
+	for _, key := range returnKeys {
+		incident, err := getIncidentRecord(ctx, db, key.incidentId)
+		if err != nil {
+			log.Errorf("GetIncidentsInArea - unable to load incident %s: %s", key.incidentId, err)
+			continue
+		}
+		pokestop, err := GetPokestopRecord(ctx, db, key.pokestopId)
+		if err != nil {
+			log.Errorf("GetIncidentsInArea - unable to load pokestop %s: %s", key.pokestopId, err)
+			continue
+		}
+		if incident == nil || pokestop == nil {
+			continue
+		}
+
+		results = append(results, &ApiIncidentResult{
+			Incident:     incident,
+			PokestopName: pokestop.Name.ValueOrZero(),
+			Latitude:     pokestop.Lat,
+			Longitude:    pokestop.Lon,
+		})
+	}
+	return results
+}
+
+// GetIncidentSummaryInArea counts the active incidents within the area matching any of the
+// filters, by stats area and character. Incidents outside every area are counted against
+// an empty area name.
+func GetIncidentSummaryInArea(ctx context.Context, db db.DbDetails, retrieveParameters ApiIncidentScan) []ApiIncidentSummary {
+	type summaryKey struct {
+		area      string
+		character int16
+	}
+	counts := make(map[summaryKey]int)
+
+	for _, key := range internalGetIncidentsInArea(retrieveParameters, 0) {
+		pokestop, err := GetPokestopRecord(ctx, db, key.pokestopId)
+		if err != nil || pokestop == nil {
+			continue
+		}
+		areas := MatchStatsGeofence(pokestop.Lat, pokestop.Lon)
+		if len(areas) == 0 {
+			counts[summaryKey{character: key.character}]++
+		}
+		for i := range areas {
+			counts[summaryKey{area: areas[i].String(), character: key.character}]++
+		}
+	}
+
+	summary := make([]ApiIncidentSummary, 0, len(counts))
+	for key, count := range counts {
+		summary = append(summary, ApiIncidentSummary{Area: key.area, Character: key.character, Count: count})
+	}
+	slices.SortFunc(summary, func(a, b ApiIncidentSummary) int {
+		return cmp.Or(cmp.Compare(a.Area, b.Area), cmp.Compare(a.Character, b.Character))
+	})
+	return summary
+}
@@ -0,0 +1,156 @@
+package decoder
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+)
+
+// ApiPokestopScan searches within the bounding box given by min and max, or within fence
+// if given
+type ApiPokestopScan struct {
+	Min        geo.Location           `json:"min"`
+	Max        geo.Location           `json:"max"`
+	Fence      []geo.ApiLocation      `json:"fence"`
+	Limit      int                    `json:"limit"`
+	DnfFilters []ApiPokestopDnfFilter `json:"filters"`
+}
+
+// ApiPokestopDnfFilter matches a pokestop when every given condition holds. Where a
+// condition is a list, any entry in the list may match. Only quests, lures, incidents and
+// showcases which have not expired are matched.
+type ApiPokestopDnfFilter struct {
+	Quests          []ApiPokestopDnfQuest    `json:"quests"`
+	LureId          []int16                  `json:"lure_id"`
+	Incidents       []ApiPokestopDnfIncident `json:"incidents"`
+	ShowcasePokemon []ApiPokemonDnfId        `json:"showcase_pokemon"`
+	PowerUpLevel    *ApiPokemonDnfMinMax8    `json:"power_up_level"`
+}
+
+// ApiPokestopDnfQuest matches the first reward of the AR quest, the non-AR quest, or
+// either if Ar is not given. A reward type of 0 matches any type.
+type ApiPokestopDnfQuest struct {
+	RewardType int16  `json:"reward_type"`
+	ItemId     *int16 `json:"item_id"`
+	PokemonId  *int16 `json:"pokemon_id"`
+	Form       *int16 `json:"form"`
+	Ar         *bool  `json:"ar"`
+}
+
+type ApiPokestopDnfIncident struct {
+	DisplayType *int16 `json:"display_type"`
+	Character   *int16 `json:"character"`
+}
+
+// ApiPokestopResult is a pokestop along with its active incidents
+type ApiPokestopResult struct {
+	*Pokestop
+	Incidents []Incident `json:"incidents"`
+}
+
+func isPokestopQuestMatch(quest *FortLookupQuest, filter *ApiPokestopDnfQuest, now int64) bool {
+	return quest.Expiry > now &&
+		(filter.RewardType == 0 || quest.RewardType == filter.RewardType) &&
+		(filter.ItemId == nil || quest.ItemId == *filter.ItemId) &&
+		(filter.PokemonId == nil || quest.PokemonId == *filter.PokemonId) &&
+		(filter.Form == nil || quest.Form == *filter.Form)
+}
+
+func isPokestopDnfMatch(fortLookup *FortLookup, filter *ApiPokestopDnfFilter, now int64) bool {
+	if filter.PowerUpLevel != nil && (fortLookup.PowerUpLevel < filter.PowerUpLevel.Min || fortLookup.PowerUpLevel > filter.PowerUpLevel.Max) {
+		return false
+	}
+
+	if len(filter.LureId) > 0 && (fortLookup.LureExpiry <= now || !slices.Contains(filter.LureId, fortLookup.Lure)) {
+		return false
+	}
+
+	if len(filter.Quests) > 0 && !slices.ContainsFunc(filter.Quests, func(quest ApiPokestopDnfQuest) bool {
+		return (quest.Ar == nil || *quest.Ar) && isPokestopQuestMatch(&fortLookup.ArQuest, &quest, now) ||
+			(quest.Ar == nil || !*quest.Ar) && isPokestopQuestMatch(&fortLookup.NoArQuest, &quest, now)
+	}) {
+		return false
+	}
+
+	if len(filter.Incidents) > 0 && !slices.ContainsFunc(fortLookup.Incidents, func(incident FortLookupIncident) bool {
+		return incident.Expiration > now && slices.ContainsFunc(filter.Incidents, func(incidentFilter ApiPokestopDnfIncident) bool {
+			return (incidentFilter.DisplayType == nil || *incidentFilter.DisplayType == incident.DisplayType) &&
+				(incidentFilter.Character == nil || *incidentFilter.Character == incident.Character)
+		})
+	}) {
+		return false
+	}
+
+	if len(filter.ShowcasePokemon) > 0 && (fortLookup.ShowcaseExpiry <= now ||
+		!slices.ContainsFunc(filter.ShowcasePokemon, func(showcase ApiPokemonDnfId) bool {
+			return showcase.Pokemon == fortLookup.ShowcasePokemon &&
+				(showcase.Form == nil || *showcase.Form == fortLookup.ShowcaseForm)
+		})) {
+		return false
+	}
+
+	return true
+}
+
+func internalGetPokestopInArea(retrieveParameters ApiPokestopScan) []string {
+	maxPokestops := config.Config.Tuning.MaxFortResults
+	if retrieveParameters.Limit > 0 && retrieveParameters.Limit < maxPokestops {
+		maxPokestops = retrieveParameters.Limit
+	}
+
+	return scanFortTree("GetPokestopInArea", retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence, maxPokestops,
+		func(fortId string, fortLookup *FortLookup, now int64, add func(string)) {
+			if fortLookup.IsGym {
+				return
+			}
+			matched := len(retrieveParameters.DnfFilters) == 0
+			for x := 0; x < len(retrieveParameters.DnfFilters) && !matched; x++ {
+				matched = isPokestopDnfMatch(fortLookup, &retrieveParameters.DnfFilters[x], now)
+			}
+			if matched {
+				add(fortId)
+			}
+		})
+}
+
+// GetPokestopInArea returns the pokestops within the area matching any of the filters,
+// with their active incidents
+func GetPokestopInArea(ctx context.Context, db db.DbDetails, retrieveParameters ApiPokestopScan) []*ApiPokestopResult {
+	returnKeys := internalGetPokestopInArea(retrieveParameters)
+	results := make([]*ApiPokestopResult, 0, len(returnKeys))
+	now := time.Now().Unix()
+
+	for _, key := range returnKeys {
+		pokestop, err := GetPokestopRecord(ctx, db, key)
+		if err != nil {
+			log.Errorf("GetPokestopInArea - unable to load pokestop %s: %s", key, err)
+			continue
+		}
+		if pokestop == nil || pokestop.Deleted {
+			continue
+		}
+
+		fortTreeMutex.RLock()
+		lookupIncidents := fortLookupCache[key].Incidents
+		fortTreeMutex.RUnlock()
+
+		incidents := []Incident{}
+		for _, lookupIncident := range lookupIncidents {
+			if lookupIncident.Expiration <= now {
+				continue
+			}
+			incident, err := getIncidentRecord(ctx, db, lookupIncident.Id)
+			if err == nil && incident != nil {
+				incidents = append(incidents, *incident)
+			}
+		}
+		results = append(results, &ApiPokestopResult{Pokestop: pokestop, Incidents: incidents})
+	}
+	return results
+}
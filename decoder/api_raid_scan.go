@@ -0,0 +1,150 @@
+package decoder
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+)
+
+// ApiRaidScan searches for active and upcoming raids within the bounding box given by min
+// and max, or within fence if given
+type ApiRaidScan struct {
+	Min        geo.Location       `json:"min"`
+	Max        geo.Location       `json:"max"`
+	Fence      []geo.ApiLocation  `json:"fence"`
+	Limit      int                `json:"limit"`
+	DnfFilters []ApiRaidDnfFilter `json:"filters"`
+}
+
+// ApiRaidDnfFilter matches a raid when every given condition holds. Remaining is the number
+// of seconds until the raid ends.
+type ApiRaidDnfFilter struct {
+	Level          *ApiPokemonDnfMinMax8 `json:"level"`
+	Pokemon        []ApiPokemonDnfId     `json:"pokemon"`
+	Alignment      []int16               `json:"alignment"`
+	Hatched        *bool                 `json:"hatched"`
+	Remaining      *ApiRaidDnfMinMax     `json:"remaining"`
+	ExRaidEligible *bool                 `json:"ex_raid_eligible"`
+}
+
+type ApiRaidDnfMinMax struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+type ApiRaidResult struct {
+	GymId          string  `json:"gym_id"`
+	GymName        string  `json:"gym_name"`
+	GymUrl         string  `json:"gym_url"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	TeamId         int64   `json:"team_id"`
+	Level          int64   `json:"level"`
+	Spawn          int64   `json:"spawn"`
+	Start          int64   `json:"start"`
+	End            int64   `json:"end"`
+	PokemonId      int64   `json:"pokemon_id"`
+	Form           int64   `json:"form"`
+	Costume        int64   `json:"costume"`
+	Gender         int64   `json:"gender"`
+	Alignment      int64   `json:"alignment"`
+	Evolution      int64   `json:"evolution"`
+	Cp             int64   `json:"cp"`
+	Move1          int64   `json:"move_1"`
+	Move2          int64   `json:"move_2"`
+	ExRaidEligible bool    `json:"ex_raid_eligible"`
+	IsExclusive    bool    `json:"is_exclusive"`
+}
+
+func isRaidDnfMatch(fortLookup *FortLookup, filter *ApiRaidDnfFilter, now int64) bool {
+	hatched := fortLookup.RaidBattleTimestamp <= now
+	remaining := fortLookup.RaidEndTimestamp - now
+
+	if filter.Level != nil && (fortLookup.RaidLevel < filter.Level.Min || fortLookup.RaidLevel > filter.Level.Max) ||
+		len(filter.Alignment) > 0 && !slices.Contains(filter.Alignment, fortLookup.RaidPokemonAlignment) ||
+		filter.Hatched != nil && hatched != *filter.Hatched ||
+		filter.Remaining != nil && (remaining < filter.Remaining.Min || remaining > filter.Remaining.Max) ||
+		filter.ExRaidEligible != nil && fortLookup.ExRaidEligible != *filter.ExRaidEligible {
+		return false
+	}
+
+	if len(filter.Pokemon) > 0 && !slices.ContainsFunc(filter.Pokemon, func(pokemon ApiPokemonDnfId) bool {
+		return pokemon.Pokemon == fortLookup.RaidPokemonId &&
+			(pokemon.Form == nil || *pokemon.Form == fortLookup.RaidPokemonForm)
+	}) {
+		return false
+	}
+
+	return true
+}
+
+func internalGetRaidsInArea(retrieveParameters ApiRaidScan) []string {
+	maxRaids := config.Config.Tuning.MaxFortResults
+	if retrieveParameters.Limit > 0 && retrieveParameters.Limit < maxRaids {
+		maxRaids = retrieveParameters.Limit
+	}
+
+	return scanFortTree("GetRaidsInArea", retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence, maxRaids,
+		func(fortId string, fortLookup *FortLookup, now int64, add func(string)) {
+			if !fortLookup.IsGym || fortLookup.RaidEndTimestamp <= now || fortLookup.RaidLevel <= 0 {
+				return
+			}
+			matched := len(retrieveParameters.DnfFilters) == 0
+			for x := 0; x < len(retrieveParameters.DnfFilters) && !matched; x++ {
+				matched = isRaidDnfMatch(fortLookup, &retrieveParameters.DnfFilters[x], now)
+			}
+			if matched {
+				add(fortId)
+			}
+		})
+}
+
+// GetRaidsInArea returns the raids and eggs which have not yet ended within the area,
+// matching any of the filters
+func GetRaidsInArea(ctx context.Context, db db.DbDetails, retrieveParameters ApiRaidScan) []*ApiRaidResult {
+	returnKeys := internalGetRaidsInArea(retrieveParameters)
+	results := make([]*ApiRaidResult, 0, len(returnKeys))
+	now := time.Now().Unix()
+
+	for _, key := range returnKeys {
+		gym, err := getGymRecord(ctx, db, key)
+		if err != nil {
+			log.Errorf("GetRaidsInArea - unable to load gym %s: %s", key, err)
+			continue
+		}
+		if gym == nil || gym.Deleted || gym.RaidEndTimestamp.ValueOrZero() <= now {
+			continue
+		}
+
+		results = append(results, &ApiRaidResult{
+			GymId:          gym.Id,
+			GymName:        gym.Name.ValueOrZero(),
+			GymUrl:         gym.Url.ValueOrZero(),
+			Latitude:       gym.Lat,
+			Longitude:      gym.Lon,
+			TeamId:         gym.TeamId.ValueOrZero(),
+			Level:          gym.RaidLevel.ValueOrZero(),
+			Spawn:          gym.RaidSpawnTimestamp.ValueOrZero(),
+			Start:          gym.RaidBattleTimestamp.ValueOrZero(),
+			End:            gym.RaidEndTimestamp.ValueOrZero(),
+			PokemonId:      gym.RaidPokemonId.ValueOrZero(),
+			Form:           gym.RaidPokemonForm.ValueOrZero(),
+			Costume:        gym.RaidPokemonCostume.ValueOrZero(),
+			Gender:         gym.RaidPokemonGender.ValueOrZero(),
+			Alignment:      gym.RaidPokemonAlignment.ValueOrZero(),
+			Evolution:      gym.RaidPokemonEvolution.ValueOrZero(),
+			Cp:             gym.RaidPokemonCp.ValueOrZero(),
+			Move1:          gym.RaidPokemonMove1.ValueOrZero(),
+			Move2:          gym.RaidPokemonMove2.ValueOrZero(),
+			ExRaidEligible: gym.ExRaidEligible.ValueOrZero() != 0,
+			IsExclusive:    gym.RaidIsExclusive.ValueOrZero() != 0,
+		})
+	}
+	return results
+}
@@ -0,0 +1,163 @@
+package decoder
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+)
+
+// ApiRouteScan searches for routes starting within the bounding box given by min and max,
+// or within fence if given, and optionally starting or ending at the given forts. When no
+// area is given, only the forts are matched.
+type ApiRouteScan struct {
+	Min         geo.Location      `json:"min"`
+	Max         geo.Location      `json:"max"`
+	Fence       []geo.ApiLocation `json:"fence"`
+	StartFortId string            `json:"start_fort_id"`
+	EndFortId   string            `json:"end_fort_id"`
+	Limit       int               `json:"limit"`
+}
+
+// ApiRouteResult is a route with its waypoints decoded into a GeoJSON LineString. It is
+// also the payload of the route webhook.
+type ApiRouteResult struct {
+	Id               string            `json:"id"`
+	Name             string            `json:"name"`
+	Description      string            `json:"description"`
+	Type             int8              `json:"type"`
+	DistanceMeters   int64             `json:"distance_meters"`
+	DurationSeconds  int64             `json:"duration_seconds"`
+	Reversible       bool              `json:"reversible"`
+	Image            string            `json:"image"`
+	ImageBorderColor string            `json:"image_border_color"`
+	StartFortId      string            `json:"start_fort_id"`
+	StartImage       string            `json:"start_image"`
+	StartLat         float64           `json:"start_lat"`
+	StartLon         float64           `json:"start_lon"`
+	EndFortId        string            `json:"end_fort_id"`
+	EndImage         string            `json:"end_image"`
+	EndLat           float64           `json:"end_lat"`
+	EndLon           float64           `json:"end_lon"`
+	Tags             []string          `json:"tags"`
+	Path             *geojson.Geometry `json:"path"`
+	Version          int64             `json:"version"`
+	Updated          int64             `json:"updated"`
+}
+
+// routeWaypoint picks the position out of a stored RouteWaypointProto
+type routeWaypoint struct {
+	LatDegrees float64 `json:"lat_degrees"`
+	LngDegrees float64 `json:"lng_degrees"`
+}
+
+func buildRouteResult(route *Route) *ApiRouteResult {
+	result := &ApiRouteResult{
+		Id:               route.Id,
+		Name:             route.Name,
+		Description:      route.Description,
+		Type:             route.Type,
+		DistanceMeters:   route.DistanceMeters,
+		DurationSeconds:  route.DurationSeconds,
+		Reversible:       route.Reversible,
+		Image:            route.Image,
+		ImageBorderColor: route.ImageBorderColor,
+		StartFortId:      route.StartFortId,
+		StartImage:       route.StartImage,
+		StartLat:         route.StartLat,
+		StartLon:         route.StartLon,
+		EndFortId:        route.EndFortId,
+		EndImage:         route.EndImage,
+		EndLat:           route.EndLat,
+		EndLon:           route.EndLon,
+		Tags:             []string{},
+		Version:          route.Version,
+		Updated:          route.Updated,
+	}
+
+	if route.Tags.Valid {
+		if err := json.Unmarshal([]byte(route.Tags.String), &result.Tags); err != nil {
+			log.Warnf("Route %s: unable to decode tags: %s", route.Id, err)
+		}
+	}
+
+	var waypoints []routeWaypoint
+	if err := json.Unmarshal([]byte(route.Waypoints), &waypoints); err != nil {
+		log.Warnf("Route %s: unable to decode waypoints: %s", route.Id, err)
+	}
+	lineString := make(orb.LineString, 0, len(waypoints))
+	for _, waypoint := range waypoints {
+		lineString = append(lineString, orb.Point{waypoint.LngDegrees, waypoint.LatDegrees})
+	}
+	result.Path = geojson.NewGeometry(lineString)
+
+	return result
+}
+
+// GetRoutesInArea returns the routes matching the area and forts of the scan
+func GetRoutesInArea(ctx context.Context, dbDetails db.DbDetails, retrieveParameters ApiRouteScan) ([]*ApiRouteResult, error) {
+	start := time.Now()
+
+	maxRoutes := config.Config.Tuning.MaxFortResults
+	if retrieveParameters.Limit > 0 && retrieveParameters.Limit < maxRoutes {
+		maxRoutes = retrieveParameters.Limit
+	}
+
+	minLocation, maxLocation, geofence := fortScanArea(retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence)
+	hasArea := geofence != nil || minLocation != maxLocation
+
+	var conditions []string
+	var args []interface{}
+	if hasArea {
+		conditions = append(conditions, "start_lat BETWEEN ? AND ? AND start_lon BETWEEN ? AND ?")
+		args = append(args, minLocation.Latitude, maxLocation.Latitude, minLocation.Longitude, maxLocation.Longitude)
+	}
+	if retrieveParameters.StartFortId != "" {
+		conditions = append(conditions, "start_fort_id = ?")
+		args = append(args, retrieveParameters.StartFortId)
+	}
+	if retrieveParameters.EndFortId != "" {
+		conditions = append(conditions, "end_fort_id = ?")
+		args = append(args, retrieveParameters.EndFortId)
+	}
+
+	query := "SELECT * FROM route"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// the fence is checked afterwards, so only the bounding box can be limited in the query
+	if geofence == nil {
+		query += " LIMIT ?"
+		args = append(args, maxRoutes)
+	}
+
+	var routes []Route
+	err := dbDetails.GeneralDb.SelectContext(ctx, &routes, query, args...)
+	statsCollector.IncDbQuery("select route-scan", err)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ApiRouteResult, 0, len(routes))
+	for i := range routes {
+		route := &routes[i]
+		if geofence != nil && !geofence.Contains(geo.Location{Latitude: route.StartLat, Longitude: route.StartLon}) {
+			continue
+		}
+		results = append(results, buildRouteResult(route))
+		if len(results) >= maxRoutes {
+			break
+		}
+	}
+
+	log.Infof("GetRoutesInArea - scan time %s, %d scanned, %d returned", time.Since(start), len(routes), len(results))
+	return results, nil
+}
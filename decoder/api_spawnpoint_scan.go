@@ -0,0 +1,174 @@
+package decoder
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+)
+
+// ApiSpawnpointScan searches for spawnpoints within the bounding box given by min and max,
+// or within fence if given. UnknownDespawn limits the result to spawnpoints without (or
+// with) a known despawn second, and NotSeenDays to those not seen for that many days.
+type ApiSpawnpointScan struct {
+	Min            geo.Location      `json:"min"`
+	Max            geo.Location      `json:"max"`
+	Fence          []geo.ApiLocation `json:"fence"`
+	Limit          int               `json:"limit"`
+	UnknownDespawn *bool             `json:"unknown_despawn"`
+	NotSeenDays    int               `json:"not_seen_days"`
+}
+
+type ApiSpawnpointResult struct {
+	Id          int64    `db:"id" json:"id"`
+	Lat         float64  `db:"lat" json:"lat"`
+	Lon         float64  `db:"lon" json:"lon"`
+	DespawnSec  null.Int `db:"despawn_sec" json:"despawn_sec"`
+	TthVerified bool     `db:"-" json:"tth_verified"`
+	FirstSeen   int64    `db:"first_seen" json:"first_seen"`
+	LastSeen    int64    `db:"last_seen" json:"last_seen"`
+	Updated     int64    `db:"updated" json:"updated"`
+	SeenMinutes uint64   `db:"seen_minutes" json:"-"`
+	GoneMinutes uint64   `db:"gone_minutes" json:"-"`
+	SpawnPattern
+}
+
+// GetSpawnpointsInArea returns the spawnpoints within the area matching the scan
+func GetSpawnpointsInArea(ctx context.Context, dbDetails db.DbDetails, retrieveParameters ApiSpawnpointScan) ([]*ApiSpawnpointResult, error) {
+	maxSpawnpoints := config.Config.Tuning.MaxSpawnpoints
+	if retrieveParameters.Limit > 0 && retrieveParameters.Limit < maxSpawnpoints {
+		maxSpawnpoints = retrieveParameters.Limit
+	}
+	return internalGetSpawnpointsInArea(ctx, dbDetails, retrieveParameters, maxSpawnpoints)
+}
+
+// internalGetSpawnpointsInArea returns up to maxSpawnpoints spawnpoints within the area
+// matching the scan, or every one if maxSpawnpoints is 0
+func internalGetSpawnpointsInArea(ctx context.Context, dbDetails db.DbDetails, retrieveParameters ApiSpawnpointScan, maxSpawnpoints int) ([]*ApiSpawnpointResult, error) {
+	start := time.Now()
+	minLocation, maxLocation, geofence := fortScanArea(retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence)
+
+	query := "SELECT id, lat, lon, despawn_sec, first_seen, last_seen, updated, seen_minutes, gone_minutes FROM spawnpoint " +
+		"WHERE lat BETWEEN ? AND ? AND lon BETWEEN ? AND ?"
+	args := []interface{}{minLocation.Latitude, maxLocation.Latitude, minLocation.Longitude, maxLocation.Longitude}
+	if retrieveParameters.UnknownDespawn != nil {
+		if *retrieveParameters.UnknownDespawn {
+			query += " AND despawn_sec IS NULL"
+		} else {
+			query += " AND despawn_sec IS NOT NULL"
+		}
+	}
+	if retrieveParameters.NotSeenDays > 0 {
+		query += " AND last_seen < ?"
+		args = append(args, start.Unix()-int64(retrieveParameters.NotSeenDays)*24*60*60)
+	}
+	// the fence is checked afterwards, so only the bounding box can be limited in the query
+	if geofence == nil && maxSpawnpoints > 0 {
+		query += " LIMIT ?"
+		args = append(args, maxSpawnpoints)
+	}
+
+	var spawnpoints []*ApiSpawnpointResult
+	err := dbDetails.GeneralDb.SelectContext(ctx, &spawnpoints, query, args...)
+	statsCollector.IncDbQuery("select spawnpoint-scan", err)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ApiSpawnpointResult, 0, len(spawnpoints))
+	for _, spawnpoint := range spawnpoints {
+		if geofence != nil && !geofence.Contains(geo.Location{Latitude: spawnpoint.Lat, Longitude: spawnpoint.Lon}) {
+			continue
+		}
+		spawnpoint.TthVerified = spawnpoint.DespawnSec.Valid
+		spawnpoint.SpawnPattern = inferSpawnPattern(spawnpoint.SeenMinutes, spawnpoint.GoneMinutes, spawnpoint.DespawnSec)
+		results = append(results, spawnpoint)
+		if maxSpawnpoints > 0 && len(results) >= maxSpawnpoints {
+			break
+		}
+	}
+
+	log.Infof("GetSpawnpointsInArea - scan time %s, %d scanned, %d returned", time.Since(start), len(spawnpoints), len(results))
+	return results, nil
+}
+
+// ApiUpcomingSpawn is the predicted next appearance of a pokemon at a spawnpoint
+type ApiUpcomingSpawn struct {
+	Id          int64   `json:"id"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+	SpawnTime   int64   `json:"spawn_time"`
+	DespawnTime int64   `json:"despawn_time"`
+	SpawnType   string  `json:"spawn_type"`
+	Confidence  float64 `json:"confidence"`
+}
+
+// GetUpcomingSpawnsInArea predicts the spawns within the area appearing between from and
+// to, ordered by time. Only spawnpoints with a known despawn second can be predicted, and
+// those whose pattern is not yet known are taken to be 30 minute spawns.
+func GetUpcomingSpawnsInArea(ctx context.Context, dbDetails db.DbDetails, retrieveParameters ApiSpawnpointScan, from, to int64) ([]*ApiUpcomingSpawn, error) {
+	knownDespawn := false
+	retrieveParameters.UnknownDespawn = &knownDespawn
+	retrieveParameters.NotSeenDays = 0
+
+	// every spawnpoint is needed before sorting by time, so the limit is applied afterwards
+	spawnpoints, err := internalGetSpawnpointsInArea(ctx, dbDetails, retrieveParameters, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// despawn seconds are local time, so the hour is found from the local minute and second,
+	// which differs from UTC in half hour time zones
+	fromTime := time.Unix(from, 0)
+	hourStart := from - int64(fromTime.Minute()*60+fromTime.Second())
+
+	results := []*ApiUpcomingSpawn{}
+	for _, spawnpoint := range spawnpoints {
+		// the seconds of the hour each window starts, and how long it lasts
+		despawnSec := spawnpoint.DespawnSec.Int64
+		windows := [][2]int64{{(despawnSec + 1800) % 3600, 1800}}
+		switch spawnpoint.SpawnType {
+		case SpawnType60:
+			windows = [][2]int64{{despawnSec, 3600}}
+		case SpawnTypeDouble:
+			windows = [][2]int64{
+				{spawnpoint.WindowStart.Int64, (spawnpoint.WindowEnd.Int64 - spawnpoint.WindowStart.Int64 + 3600) % 3600},
+				{spawnpoint.SecondWindowStart.Int64, (despawnSec - spawnpoint.SecondWindowStart.Int64 + 3600) % 3600},
+			}
+		}
+
+		for _, window := range windows {
+			// the first spawn at or after from, then each hour until to
+			spawnTime := hourStart + window[0]
+			if spawnTime < from {
+				spawnTime += 3600
+			}
+			for ; spawnTime <= to; spawnTime += 3600 {
+				results = append(results, &ApiUpcomingSpawn{
+					Id:          spawnpoint.Id,
+					Lat:         spawnpoint.Lat,
+					Lon:         spawnpoint.Lon,
+					SpawnTime:   spawnTime,
+					DespawnTime: spawnTime + window[1],
+					SpawnType:   spawnpoint.SpawnType,
+					Confidence:  spawnpoint.Confidence,
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(results, func(a, b *ApiUpcomingSpawn) int {
+		return cmp.Or(cmp.Compare(a.SpawnTime, b.SpawnTime), cmp.Compare(a.Id, b.Id))
+	})
+	if limit := retrieveParameters.Limit; limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,140 @@
+package decoder
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+)
+
+// ApiStationScan searches for active stations within the bounding box given by min and max,
+// or within fence if given
+type ApiStationScan struct {
+	Min        geo.Location          `json:"min"`
+	Max        geo.Location          `json:"max"`
+	Fence      []geo.ApiLocation     `json:"fence"`
+	Limit      int                   `json:"limit"`
+	DnfFilters []ApiStationDnfFilter `json:"filters"`
+}
+
+// ApiStationDnfFilter matches a station when every given condition holds. Battle conditions
+// only match stations with a battle which has not yet ended. Time windows are unix
+// timestamps.
+type ApiStationDnfFilter struct {
+	BattleLevel      *ApiPokemonDnfMinMax8 `json:"battle_level"`
+	BattlePokemon    []ApiPokemonDnfId     `json:"battle_pokemon"`
+	BreadMode        []int16               `json:"bread_mode"`
+	StartTime        *ApiRaidDnfMinMax     `json:"start_time"`
+	EndTime          *ApiRaidDnfMinMax     `json:"end_time"`
+	BattleStart      *ApiRaidDnfMinMax     `json:"battle_start"`
+	BattleEnd        *ApiRaidDnfMinMax     `json:"battle_end"`
+	StationedPokemon *ApiRaidDnfMinMax     `json:"stationed_pokemon"`
+}
+
+func isInWindow(value int64, window *ApiRaidDnfMinMax) bool {
+	return window == nil || value >= window.Min && value <= window.Max
+}
+
+func isStationDnfMatch(station *Station, filter *ApiStationDnfFilter, now int64) bool {
+	if !isInWindow(station.StartTime, filter.StartTime) ||
+		!isInWindow(station.EndTime, filter.EndTime) ||
+		!isInWindow(station.TotalStationedPokemon.ValueOrZero(), filter.StationedPokemon) {
+		return false
+	}
+
+	if filter.BattleLevel == nil && len(filter.BattlePokemon) == 0 && len(filter.BreadMode) == 0 &&
+		filter.BattleStart == nil && filter.BattleEnd == nil {
+		return true
+	}
+	if !station.BattleEnd.Valid || station.BattleEnd.Int64 <= now {
+		return false
+	}
+
+	battleLevel := station.BattleLevel.ValueOrZero()
+	if filter.BattleLevel != nil && (battleLevel < int64(filter.BattleLevel.Min) || battleLevel > int64(filter.BattleLevel.Max)) ||
+		len(filter.BreadMode) > 0 && !slices.Contains(filter.BreadMode, int16(station.BattlePokemonBreadMode.ValueOrZero())) ||
+		!isInWindow(station.BattleStart.ValueOrZero(), filter.BattleStart) ||
+		!isInWindow(station.BattleEnd.ValueOrZero(), filter.BattleEnd) {
+		return false
+	}
+
+	if len(filter.BattlePokemon) > 0 && !slices.ContainsFunc(filter.BattlePokemon, func(pokemon ApiPokemonDnfId) bool {
+		return int64(pokemon.Pokemon) == station.BattlePokemonId.ValueOrZero() &&
+			(pokemon.Form == nil || int64(*pokemon.Form) == station.BattlePokemonForm.ValueOrZero())
+	}) {
+		return false
+	}
+
+	return true
+}
+
+// GetStationsInArea returns the active stations within the area matching any of the
+// filters. Active stations are found in the database, as a station can last longer than it
+// is held in the cache, and the cached copy is preferred where there is one.
+func GetStationsInArea(ctx context.Context, db db.DbDetails, retrieveParameters ApiStationScan) []*Station {
+	start := time.Now()
+	now := start.Unix()
+
+	minLocation, maxLocation, geofence := fortScanArea(retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence)
+
+	maxStations := config.Config.Tuning.MaxFortResults
+	if retrieveParameters.Limit > 0 && retrieveParameters.Limit < maxStations {
+		maxStations = retrieveParameters.Limit
+	}
+
+	query := "SELECT " + stationColumns + " FROM station " +
+		"WHERE lat >= ? AND lat <= ? AND lon >= ? AND lon <= ? AND end_time > ? AND is_inactive = 0"
+	args := []interface{}{minLocation.Latitude, maxLocation.Latitude, minLocation.Longitude, maxLocation.Longitude, now}
+	// the fence and filters are checked afterwards, so the query can only be limited without them
+	if geofence == nil && len(retrieveParameters.DnfFilters) == 0 {
+		query += " LIMIT ?"
+		args = append(args, maxStations)
+	}
+
+	var stations []Station
+	err := db.GeneralDb.SelectContext(ctx, &stations, query, args...)
+	statsCollector.IncDbQuery("select station-area", err)
+	if err != nil {
+		log.Errorf("GetStationsInArea - unable to find stations: %s", err)
+		return []*Station{}
+	}
+
+	stationsExamined := 0
+	results := []*Station{}
+
+	for i := range stations {
+		station := &stations[i]
+		// the cached station may be more recent than the database
+		if cachedStation := stationCache.Get(station.Id); cachedStation != nil {
+			*station = cachedStation.Value()
+		}
+		if station.IsInactive || station.EndTime <= now {
+			continue
+		}
+		if geofence != nil && !geofence.Contains(geo.Location{Latitude: station.Lat, Longitude: station.Lon}) {
+			continue
+		}
+		stationsExamined++
+
+		matched := len(retrieveParameters.DnfFilters) == 0
+		for x := 0; x < len(retrieveParameters.DnfFilters) && !matched; x++ {
+			matched = isStationDnfMatch(station, &retrieveParameters.DnfFilters[x], now)
+		}
+
+		if matched {
+			results = append(results, station)
+			if len(results) >= maxStations {
+				log.Infof("GetStationsInArea - result would exceed maximum size (%d), stopping scan", maxStations)
+				break
+			}
+		}
+	}
+
+	log.Infof("GetStationsInArea - scan time %s, %d scanned, %d returned", time.Since(start), stationsExamined, len(results))
+	return results
+}
@@ -0,0 +1,218 @@
+package decoder
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"golbat/db"
+	"golbat/geo"
+	"golbat/pogo"
+)
+
+// weatherBoostedTypes lists the pokemon types boosted by each gameplay condition
+var weatherBoostedTypes = map[pogo.GameplayWeatherProto_WeatherCondition][]pogo.HoloPokemonType{
+	pogo.GameplayWeatherProto_CLEAR: {
+		pogo.HoloPokemonType_POKEMON_TYPE_GRASS, pogo.HoloPokemonType_POKEMON_TYPE_GROUND, pogo.HoloPokemonType_POKEMON_TYPE_FIRE,
+	},
+	pogo.GameplayWeatherProto_RAINY: {
+		pogo.HoloPokemonType_POKEMON_TYPE_WATER, pogo.HoloPokemonType_POKEMON_TYPE_ELECTRIC, pogo.HoloPokemonType_POKEMON_TYPE_BUG,
+	},
+	pogo.GameplayWeatherProto_PARTLY_CLOUDY: {
+		pogo.HoloPokemonType_POKEMON_TYPE_NORMAL, pogo.HoloPokemonType_POKEMON_TYPE_ROCK,
+	},
+	pogo.GameplayWeatherProto_OVERCAST: {
+		pogo.HoloPokemonType_POKEMON_TYPE_FAIRY, pogo.HoloPokemonType_POKEMON_TYPE_FIGHTING, pogo.HoloPokemonType_POKEMON_TYPE_POISON,
+	},
+	pogo.GameplayWeatherProto_WINDY: {
+		pogo.HoloPokemonType_POKEMON_TYPE_DRAGON, pogo.HoloPokemonType_POKEMON_TYPE_FLYING, pogo.HoloPokemonType_POKEMON_TYPE_PSYCHIC,
+	},
+	pogo.GameplayWeatherProto_SNOW: {
+		pogo.HoloPokemonType_POKEMON_TYPE_ICE, pogo.HoloPokemonType_POKEMON_TYPE_STEEL,
+	},
+	pogo.GameplayWeatherProto_FOG: {
+		pogo.HoloPokemonType_POKEMON_TYPE_DARK, pogo.HoloPokemonType_POKEMON_TYPE_GHOST,
+	},
+}
+
+// ApiWeatherScan searches for weather cells intersecting the bounding box given by min and
+// max, or the fence if given
+type ApiWeatherScan struct {
+	Min   geo.Location      `json:"min"`
+	Max   geo.Location      `json:"max"`
+	Fence []geo.ApiLocation `json:"fence"`
+}
+
+type ApiWeatherResult struct {
+	Id                int64         `json:"s2_cell_id"`
+	Latitude          float64       `json:"latitude"`
+	Longitude         float64       `json:"longitude"`
+	Polygon           [4][2]float64 `json:"polygon"`
+	GameplayCondition int64         `json:"gameplay_condition"`
+	BoostedTypes      []int32       `json:"boosted_types"`
+	Severity          int64         `json:"severity"`
+	WarnWeather       bool          `json:"warn_weather"`
+	Updated           int64         `json:"updated"`
+}
+
+type ApiWeatherHistory struct {
+	Id        int64                     `json:"s2_cell_id"`
+	Latitude  float64                   `json:"latitude"`
+	Longitude float64                   `json:"longitude"`
+	Changes   []ApiWeatherHistoryChange `json:"changes"`
+}
+
+type ApiWeatherHistoryChange struct {
+	Changed           int64     `db:"changed" json:"changed"`
+	GameplayCondition null.Int  `db:"gameplay_condition" json:"gameplay_condition"`
+	Severity          null.Int  `db:"severity" json:"severity"`
+	WarnWeather       null.Bool `db:"warn_weather" json:"warn_weather"`
+}
+
+// weatherCellCoverer finds the level 10 weather cells covering an area. MaxCells is only a
+// guide, as a covering at a single level cannot be reduced to fewer cells.
+var weatherCellCoverer = &s2.RegionCoverer{MinLevel: 10, MaxLevel: 10, MaxCells: 1000}
+
+const weatherScanBatchSize = 1000
+
+// maxWeatherScanCells caps the weather cells a single scan may cover, around 800,000
+// square kilometres
+const maxWeatherScanCells = 10000
+
+// ErrWeatherAreaTooLarge is returned by GetWeatherInArea when the area covers more than
+// maxWeatherScanCells weather cells
+var ErrWeatherAreaTooLarge = errors.New("area covers too many weather cells")
+
+func isWeatherCellInFence(cell s2.Cell, geofence *geo.Geofence, fence []geo.ApiLocation) bool {
+	center := s2.LatLngFromPoint(cell.Center())
+	if geofence.Contains(geo.Location{Latitude: center.Lat.Degrees(), Longitude: center.Lng.Degrees()}) {
+		return true
+	}
+	for i := 0; i < 4; i++ {
+		vertex := s2.LatLngFromPoint(cell.Vertex(i))
+		if geofence.Contains(geo.Location{Latitude: vertex.Lat.Degrees(), Longitude: vertex.Lng.Degrees()}) {
+			return true
+		}
+	}
+	// a fence smaller than the cell may sit entirely inside it
+	for _, location := range fence {
+		if cell.ContainsPoint(s2.PointFromLatLng(s2.LatLngFromDegrees(location.Latitude, location.Longitude))) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetWeatherInArea returns the weather cells intersecting the area. With a fence, a cell is
+// returned when its centre or a corner is within the fence, or the fence lies within it. An
+// area covering too many cells is refused with ErrWeatherAreaTooLarge.
+func GetWeatherInArea(ctx context.Context, dbDetails db.DbDetails, retrieveParameters ApiWeatherScan) ([]*ApiWeatherResult, error) {
+	start := time.Now()
+	minLocation, maxLocation, geofence := fortScanArea(retrieveParameters.Min, retrieveParameters.Max, retrieveParameters.Fence)
+
+	rect := s2.RectFromLatLng(s2.LatLngFromDegrees(minLocation.Latitude, minLocation.Longitude)).
+		AddPoint(s2.LatLngFromDegrees(maxLocation.Latitude, maxLocation.Longitude))
+	// estimated first, as covering a very large area is itself expensive
+	if rect.Area()/s2.AvgAreaMetric.Value(10) > maxWeatherScanCells {
+		return nil, ErrWeatherAreaTooLarge
+	}
+	covering := weatherCellCoverer.Covering(rect)
+	if len(covering) > maxWeatherScanCells {
+		return nil, ErrWeatherAreaTooLarge
+	}
+
+	cellIds := make([]int64, 0, len(covering))
+	for _, cellId := range covering {
+		cellIds = append(cellIds, int64(cellId))
+	}
+
+	// a large area still covers too many cells for a single query
+	var weathers []Weather
+	for i := 0; i < len(cellIds); i += weatherScanBatchSize {
+		query, args, _ := sqlx.In("SELECT id, latitude, longitude, level, gameplay_condition, wind_direction, cloud_level, rain_level, wind_level, snow_level, fog_level, special_effect_level, severity, warn_weather, updated "+
+			"FROM weather WHERE id IN (?)", cellIds[i:min(i+weatherScanBatchSize, len(cellIds))])
+		query = dbDetails.GeneralDb.Rebind(query)
+
+		var batch []Weather
+		err := dbDetails.GeneralDb.SelectContext(ctx, &batch, query, args...)
+		statsCollector.IncDbQuery("select weather-scan", err)
+		if err != nil {
+			return nil, err
+		}
+		weathers = append(weathers, batch...)
+	}
+
+	results := []*ApiWeatherResult{}
+	for i := range weathers {
+		weather := &weathers[i]
+		cell := s2.CellFromCellID(s2.CellID(weather.Id))
+		if geofence != nil && !isWeatherCellInFence(cell, geofence, retrieveParameters.Fence) {
+			continue
+		}
+
+		boostedTypes := []int32{}
+		for _, pokemonType := range weatherBoostedTypes[pogo.GameplayWeatherProto_WeatherCondition(weather.GameplayCondition.ValueOrZero())] {
+			boostedTypes = append(boostedTypes, int32(pokemonType))
+		}
+		results = append(results, &ApiWeatherResult{
+			Id:                weather.Id,
+			Latitude:          weather.Latitude,
+			Longitude:         weather.Longitude,
+			Polygon:           weatherCellPolygon(weather.Id),
+			GameplayCondition: weather.GameplayCondition.ValueOrZero(),
+			BoostedTypes:      boostedTypes,
+			Severity:          weather.Severity.ValueOrZero(),
+			WarnWeather:       weather.WarnWeather.ValueOrZero(),
+			Updated:           weather.Updated,
+		})
+	}
+
+	log.Infof("GetWeatherInArea - scan time %s, %d scanned, %d returned", time.Since(start), len(weathers), len(results))
+	return results, nil
+}
+
+// GetWeatherHistory returns the condition changes of each weather cell over the last hours,
+// oldest first. A cellId of 0 returns every cell.
+func GetWeatherHistory(ctx context.Context, dbDetails db.DbDetails, hours int, cellId int64) ([]*ApiWeatherHistory, error) {
+	type historyRow struct {
+		Id int64 `db:"id"`
+		ApiWeatherHistoryChange
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour).Unix()
+	query := "SELECT id, changed, gameplay_condition, severity, warn_weather FROM weather_history WHERE changed >= ?"
+	args := []interface{}{since}
+	if cellId != 0 {
+		query += " AND id = ?"
+		args = append(args, cellId)
+	}
+	query += " ORDER BY id, changed"
+
+	var rows []historyRow
+	err := dbDetails.GeneralDb.SelectContext(ctx, &rows, query, args...)
+	statsCollector.IncDbQuery("select weather_history", err)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*ApiWeatherHistory{}
+	var current *ApiWeatherHistory
+	for _, row := range rows {
+		if current == nil || current.Id != row.Id {
+			center := s2.LatLngFromPoint(s2.CellFromCellID(s2.CellID(row.Id)).Center())
+			current = &ApiWeatherHistory{
+				Id:        row.Id,
+				Latitude:  center.Lat.Degrees(),
+				Longitude: center.Lng.Degrees(),
+			}
+			results = append(results, current)
+		}
+		current.Changes = append(current.Changes, row.ApiWeatherHistoryChange)
+	}
+	return results, nil
+}
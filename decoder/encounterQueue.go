@@ -0,0 +1,263 @@
+package decoder
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v2"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+
+	"golbat/config"
+	"golbat/db"
+	"golbat/geo"
+)
+
+const (
+	EncounterPrioritySpecies       = "species"
+	EncounterPriorityNewSpawnpoint = "new_spawnpoint"
+	EncounterPriorityTimeRemaining = "time_remaining"
+	EncounterPriorityDistance      = "distance"
+)
+
+var defaultEncounterPriority = []string{
+	EncounterPrioritySpecies,
+	EncounterPriorityNewSpawnpoint,
+	EncounterPriorityTimeRemaining,
+	EncounterPriorityDistance,
+}
+
+const defaultEncounterQueueLimit = 10
+
+// encounteredSpawnpoints holds the spawnpoints a pokemon has been encountered at. It is
+// seeded on start from the pokemon with IVs still in the database, so with
+// pokemon_memory_only it only holds spawnpoints encountered since Golbat started.
+var encounteredSpawnpoints = xsync.NewIntegerMapOf[int64, struct{}]()
+
+// encounterQueueMutex makes choosing and leasing queue items atomic, so that two devices
+// cannot be handed the same pokemon
+var encounterQueueMutex sync.Mutex
+
+// ApiEncounterQueueRequest asks for pokemon without IVs within the bounding box given by
+// min and max, within fence, or within the named area (as in the config, such as
+// "London/Chelsea" or "London/*") if given, to be encountered by device. Priority orders the
+// queue by any of species (in the order of Pokemon, with other species after), new
+// spawnpoint (never encountered first), time remaining (least first) and distance in km
+// from Location (nearest first).
+type ApiEncounterQueueRequest struct {
+	Min           geo.Location      `json:"min"`
+	Max           geo.Location      `json:"max"`
+	Fence         []geo.ApiLocation `json:"fence"`
+	Area          string            `json:"area"`
+	Limit         int               `json:"limit"`
+	Device        string            `json:"device"`
+	Location      *geo.Location     `json:"location"`
+	Pokemon       []int16           `json:"pokemon"`
+	Priority      []string          `json:"priority"`
+	MinRemaining  int64             `json:"min_remaining"`
+	IncludeNearby bool              `json:"include_nearby"`
+}
+
+type ApiEncounterQueueItem struct {
+	EncounterId             string   `json:"encounter_id"`
+	PokemonId               int16    `json:"pokemon_id"`
+	Form                    int16    `json:"form"`
+	Lat                     float64  `json:"lat"`
+	Lon                     float64  `json:"lon"`
+	SpawnId                 null.Int `json:"spawn_id"`
+	SeenType                string   `json:"seen_type"`
+	ExpireTimestamp         int64    `json:"expire_timestamp"`
+	ExpireTimestampVerified bool     `json:"expire_timestamp_verified"`
+	NewSpawnpoint           bool     `json:"new_spawnpoint"`
+	Distance                float64  `json:"distance"`
+	LeaseExpiry             int64    `json:"lease_expiry"`
+
+	pokemonId uint64
+	priority  int
+}
+
+func compareEncounterQueueItems(priority []string, a, b *ApiEncounterQueueItem) int {
+	for _, key := range priority {
+		var c int
+		switch key {
+		case EncounterPrioritySpecies:
+			c = cmp.Compare(a.priority, b.priority)
+		case EncounterPriorityNewSpawnpoint:
+			if a.NewSpawnpoint != b.NewSpawnpoint {
+				c = 1
+				if a.NewSpawnpoint {
+					c = -1
+				}
+			}
+		case EncounterPriorityTimeRemaining:
+			c = cmp.Compare(a.ExpireTimestamp, b.ExpireTimestamp)
+		case EncounterPriorityDistance:
+			c = cmp.Compare(a.Distance, b.Distance)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.pokemonId, b.pokemonId)
+}
+
+// GetEncounterQueue returns the pokemon without IVs in the area that device should
+// encounter next, leasing them to the device so they are not handed to another. A device
+// asking again is handed its own leased pokemon again.
+func GetEncounterQueue(request ApiEncounterQueueRequest) ([]*ApiEncounterQueueItem, error) {
+	start := time.Now()
+	now := start.Unix()
+
+	priority := request.Priority
+	if len(priority) == 0 {
+		priority = defaultEncounterPriority
+		if request.Location == nil {
+			priority = slices.DeleteFunc(slices.Clone(priority), func(key string) bool {
+				return key == EncounterPriorityDistance
+			})
+		}
+	}
+	for _, key := range priority {
+		if !slices.Contains(defaultEncounterPriority, key) {
+			return nil, fmt.Errorf("unknown priority '%s'", key)
+		}
+	}
+	if slices.Contains(priority, EncounterPriorityDistance) && request.Location == nil {
+		return nil, fmt.Errorf("distance priority needs a location")
+	}
+
+	limit := defaultEncounterQueueLimit
+	if request.Limit > 0 {
+		limit = min(request.Limit, config.Config.Tuning.MaxPokemonResults)
+	}
+
+	seenTypes := []string{SeenType_Wild}
+	if request.IncludeNearby {
+		seenTypes = append(seenTypes, SeenType_NearbyStop, SeenType_Cell)
+	}
+
+	minLocation, maxLocation, geofence := fortScanArea(request.Min, request.Max, request.Fence)
+	var areaNames []geo.AreaName
+	if request.Area != "" {
+		areaNames = config.AreaNamesFromStrings([]string{request.Area})
+		bbox, found := StatsGeofenceBounds(areaNames)
+		if !found {
+			return nil, fmt.Errorf("unknown area '%s'", request.Area)
+		}
+		minLocation = geo.Location{Latitude: bbox.MinimumLatitude, Longitude: bbox.MinimumLongitude}
+		maxLocation = geo.Location{Latitude: bbox.MaximumLatitude, Longitude: bbox.MaximumLongitude}
+	}
+
+	var candidates []uint64
+	pokemonTreeMutex.RLock()
+	pokemonTree.Search([2]float64{minLocation.Longitude, minLocation.Latitude}, [2]float64{maxLocation.Longitude, maxLocation.Latitude},
+		func(min, max [2]float64, pokemonId uint64) bool {
+			pokemonLookupItem, found := pokemonLookupCache.Load(pokemonId)
+			if !found || pokemonLookupItem.PokemonLookup.HasEncounterValues {
+				return true
+			}
+			if geofence != nil && !geofence.Contains(geo.Location{Latitude: min[1], Longitude: min[0]}) {
+				return true
+			}
+			if areaNames != nil && !geo.AreaMatchWithWildcards(MatchStatsGeofence(min[1], min[0]), areaNames) {
+				return true
+			}
+			candidates = append(candidates, pokemonId)
+			return true
+		})
+	pokemonTreeMutex.RUnlock()
+
+	encounterQueueMutex.Lock()
+	defer encounterQueueMutex.Unlock()
+
+	var queue []*ApiEncounterQueueItem
+	for _, pokemonId := range candidates {
+		if lease := encounterLeaseCache.Get(pokemonId); lease != nil && lease.Value() != request.Device {
+			continue
+		}
+		item := pokemonCache.Get(strconv.FormatUint(pokemonId, 10))
+		if item == nil {
+			continue
+		}
+		pokemon := item.Value()
+		if pokemon.Iv.Valid || !slices.Contains(seenTypes, pokemon.SeenType.ValueOrZero()) ||
+			pokemon.ExpireTimestamp.ValueOrZero()-now <= request.MinRemaining {
+			continue
+		}
+
+		queueItem := &ApiEncounterQueueItem{
+			EncounterId:             pokemon.Id,
+			PokemonId:               pokemon.PokemonId,
+			Form:                    int16(pokemon.Form.ValueOrZero()),
+			Lat:                     pokemon.Lat,
+			Lon:                     pokemon.Lon,
+			SpawnId:                 pokemon.SpawnId,
+			SeenType:                pokemon.SeenType.ValueOrZero(),
+			ExpireTimestamp:         pokemon.ExpireTimestamp.ValueOrZero(),
+			ExpireTimestampVerified: pokemon.ExpireTimestampVerified,
+			pokemonId:               pokemonId,
+			priority:                len(request.Pokemon),
+		}
+		if index := slices.Index(request.Pokemon, pokemon.PokemonId); index >= 0 {
+			queueItem.priority = index
+		}
+		if pokemon.SpawnId.Valid {
+			_, encountered := encounteredSpawnpoints.Load(pokemon.SpawnId.Int64)
+			queueItem.NewSpawnpoint = !encountered
+		}
+		if request.Location != nil {
+			queueItem.Distance = haversine(*request.Location, geo.Location{Latitude: pokemon.Lat, Longitude: pokemon.Lon})
+		}
+		queue = append(queue, queueItem)
+	}
+
+	slices.SortFunc(queue, func(a, b *ApiEncounterQueueItem) int {
+		return compareEncounterQueueItems(priority, a, b)
+	})
+	if len(queue) > limit {
+		queue = queue[:limit]
+	}
+
+	leaseTtl := time.Duration(config.Config.Tuning.EncounterLeaseSecs) * time.Second
+	for _, queueItem := range queue {
+		encounterLeaseCache.Set(queueItem.pokemonId, request.Device, leaseTtl)
+		queueItem.LeaseExpiry = now + int64(config.Config.Tuning.EncounterLeaseSecs)
+	}
+
+	log.Infof("GetEncounterQueue - scan time %s, %d scanned, %d returned to %s", time.Since(start), len(candidates), len(queue), request.Device)
+	return queue, nil
+}
+
+// clearEncounterLease releases the lease on a pokemon once it has been encountered, and
+// records its spawnpoint as encountered
+func clearEncounterLease(pokemon *Pokemon) {
+	pokemonId, _ := strconv.ParseUint(pokemon.Id, 10, 64)
+	encounterLeaseCache.Delete(pokemonId)
+	if pokemon.SpawnId.Valid {
+		encounteredSpawnpoints.Store(pokemon.SpawnId.Int64, struct{}{})
+	}
+}
+
+// LoadEncounteredSpawnpoints seeds the encountered spawnpoints from the pokemon with IVs
+// still in the database
+func LoadEncounteredSpawnpoints(details db.DbDetails) {
+	if config.Config.PokemonMemoryOnly {
+		return
+	}
+
+	var spawnIds []int64
+	err := details.GeneralDb.Select(&spawnIds, "SELECT DISTINCT spawn_id FROM pokemon WHERE spawn_id IS NOT NULL AND atk_iv IS NOT NULL")
+	statsCollector.IncDbQuery("select pokemon-spawnpoints", err)
+	if err != nil {
+		log.Errorf("Unable to load encountered spawnpoints: %s", err)
+		return
+	}
+	for _, spawnId := range spawnIds {
+		encounteredSpawnpoints.Store(spawnId, struct{}{})
+	}
+	log.Infof("Loaded %d encountered spawnpoints", len(spawnIds))
+}
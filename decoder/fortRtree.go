@@ -2,19 +2,57 @@ package decoder
 
 import (
 	"context"
+	"encoding/json"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/rtree"
 	"golbat/db"
+	"golbat/geo"
+	"gopkg.in/guregu/null.v4"
 	"sync"
+	"time"
 )
 
 type FortLookup struct {
-	IsGym           bool
-	Lure            int16
-	RaidLevel       int8
-	RaidPokemonId   int16
-	QuestRewardType int16
-	QuestRewardId   int16
+	IsGym                bool
+	Lure                 int16
+	LureExpiry           int64
+	ArQuest              FortLookupQuest
+	NoArQuest            FortLookupQuest
+	ShowcasePokemon      int16
+	ShowcaseForm         int16
+	ShowcaseExpiry       int64
+	Incidents            []FortLookupIncident
+	RaidLevel            int8
+	RaidPokemonId        int16
+	RaidPokemonForm      int16
+	RaidPokemonAlignment int16
+	RaidBattleTimestamp  int64
+	RaidEndTimestamp     int64
+	TeamId               int8
+	AvailableSlots       int8
+	ExRaidEligible       bool
+	InBattle             bool
+	PowerUpLevel         int8
+	QuestRewardType      int16
+	QuestRewardId        int16
+}
+
+// FortLookupQuest is the first reward of a pokestop quest
+type FortLookupQuest struct {
+	RewardType int16
+	ItemId     int16
+	PokemonId  int16
+	Form       int16
+	Expiry     int64
+}
+
+type FortLookupIncident struct {
+	Id          string
+	DisplayType int16
+	Character   int16
+	Expiration  int64
+	Confirmed   bool
+	Lineup      [3]int16
 }
 
 var fortLookupCache map[string]FortLookup
@@ -49,6 +87,25 @@ func LoadAllPokestops(details db.DbDetails) {
 		GetPokestopRecord(context.Background(), details, place.Id)
 	}
 	log.Infof("Loaded %d pokestops [finished]", count)
+
+	rows, err = details.GeneralDb.Queryx("SELECT id FROM incident WHERE expiration > UNIX_TIMESTAMP()")
+	if err != nil {
+		log.Errorf("FortRTree: Load Incidents %s", err)
+		return
+	}
+	count = 0
+	for rows.Next() {
+		count++
+		err := rows.StructScan(&place)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		incident, _ := getIncidentRecord(context.Background(), details, place.Id)
+		if incident != nil {
+			fortRtreeUpdateIncident(incident)
+		}
+	}
+	log.Infof("Loaded %d incidents [finished]", count)
 }
 
 func LoadAllGyms(details db.DbDetails) {
@@ -95,25 +152,129 @@ func fortRtreeUpdateGymOnGet(gym *Gym) {
 	}
 }
 
+// fortRtreeUpdateGymOnSave keeps the tree and lookup in step with a saved gym
+func fortRtreeUpdateGymOnSave(gym *Gym) {
+	fortTreeMutex.RLock()
+	_, inMap := fortLookupCache[gym.Id]
+	fortTreeMutex.RUnlock()
+	if !inMap {
+		addGymToTree(gym)
+	}
+	updateGymLookup(gym)
+}
+
+// fortRtreeUpdatePokestopOnSave keeps the tree and lookup in step with a saved pokestop
+func fortRtreeUpdatePokestopOnSave(pokestop *Pokestop) {
+	fortTreeMutex.RLock()
+	_, inMap := fortLookupCache[pokestop.Id]
+	fortTreeMutex.RUnlock()
+	if !inMap {
+		addPokestopToTree(pokestop)
+	}
+	updatePokestopLookup(pokestop)
+}
+
+// fortLookupQuestFromRewards reads the first reward from the quest_rewards json
+func fortLookupQuestFromRewards(rewards null.String, expiry null.Int) FortLookupQuest {
+	if !rewards.Valid {
+		return FortLookupQuest{}
+	}
+	var questRewards []struct {
+		Type int16 `json:"type"`
+		Info struct {
+			ItemId    int16 `json:"item_id"`
+			PokemonId int16 `json:"pokemon_id"`
+			FormId    int16 `json:"form_id"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal([]byte(rewards.String), &questRewards); err != nil || len(questRewards) == 0 {
+		return FortLookupQuest{}
+	}
+	return FortLookupQuest{
+		RewardType: questRewards[0].Type,
+		ItemId:     questRewards[0].Info.ItemId,
+		PokemonId:  questRewards[0].Info.PokemonId,
+		Form:       questRewards[0].Info.FormId,
+		Expiry:     expiry.ValueOrZero(),
+	}
+}
+
 func updatePokestopLookup(pokestop *Pokestop) {
-	fortTreeMutex.Lock()
-	fortLookupCache[pokestop.Id] = FortLookup{
-		IsGym: false,
-		Lure:  pokestop.LureId,
-		//		RaidLevel:       pokestop.RaidLevel,
-		//		RaidPokemonId:   pokestop.RaidPokemonId,
-		//		QuestRewardType: pokestop.QuestRewardType,
-		//		QuestRewardId:   pokestop.QuestRewardId,
+	fortLookup := FortLookup{
+		IsGym:           false,
+		Lure:            pokestop.LureId,
+		LureExpiry:      pokestop.LureExpireTimestamp.ValueOrZero(),
+		ArQuest:         fortLookupQuestFromRewards(pokestop.QuestRewards, pokestop.QuestExpiry),
+		NoArQuest:       fortLookupQuestFromRewards(pokestop.AlternativeQuestRewards, pokestop.AlternativeQuestExpiry),
+		ShowcasePokemon: int16(pokestop.ShowcasePokemon.ValueOrZero()),
+		ShowcaseForm:    int16(pokestop.ShowcasePokemonForm.ValueOrZero()),
+		ShowcaseExpiry:  pokestop.ShowcaseExpiry.ValueOrZero(),
+		PowerUpLevel:    int8(pokestop.PowerUpLevel.ValueOrZero()),
 	}
+
+	fortTreeMutex.Lock()
+	// incidents are kept up to date separately, see fortRtreeUpdateIncident
+	fortLookup.Incidents = fortLookupCache[pokestop.Id].Incidents
+	fortLookupCache[pokestop.Id] = fortLookup
 	fortTreeMutex.Unlock()
 }
 
+// fortRtreeUpdateIncident records an incident against its pokestop, dropping any which
+// have expired
+func fortRtreeUpdateIncident(incident *Incident) {
+	now := time.Now().Unix()
+
+	fortTreeMutex.Lock()
+	defer fortTreeMutex.Unlock()
+
+	fortLookup, found := fortLookupCache[incident.PokestopId]
+	if !found {
+		return
+	}
+	incidents := make([]FortLookupIncident, 0, len(fortLookup.Incidents)+1)
+	for _, existing := range fortLookup.Incidents {
+		if existing.Id != incident.Id && existing.Expiration > now {
+			incidents = append(incidents, existing)
+		}
+	}
+	if incident.ExpirationTime > now {
+		incidents = append(incidents, FortLookupIncident{
+			Id:          incident.Id,
+			DisplayType: incident.DisplayType,
+			Character:   incident.Character,
+			Expiration:  incident.ExpirationTime,
+			Confirmed:   incident.Confirmed,
+			Lineup: [3]int16{
+				int16(incident.Slot1PokemonId.ValueOrZero()),
+				int16(incident.Slot2PokemonId.ValueOrZero()),
+				int16(incident.Slot3PokemonId.ValueOrZero()),
+			},
+		})
+	}
+	fortLookup.Incidents = incidents
+	fortLookupCache[incident.PokestopId] = fortLookup
+}
+
 func updateGymLookup(gym *Gym) {
+	availableSlots := int8(6) // an unseen gym is empty, as in the gym webhook
+	if gym.AvailableSlots.Valid {
+		availableSlots = int8(gym.AvailableSlots.Int64)
+	}
+
 	fortTreeMutex.Lock()
 	fortLookupCache[gym.Id] = FortLookup{
-		IsGym:         true,
-		RaidLevel:     int8(gym.RaidLevel.ValueOrZero()),
-		RaidPokemonId: int16(gym.RaidPokemonId.ValueOrZero()),
+		IsGym:                true,
+		RaidLevel:            int8(gym.RaidLevel.ValueOrZero()),
+		RaidPokemonId:        int16(gym.RaidPokemonId.ValueOrZero()),
+		RaidPokemonForm:      int16(gym.RaidPokemonForm.ValueOrZero()),
+		RaidPokemonAlignment: int16(gym.RaidPokemonAlignment.ValueOrZero()),
+		RaidBattleTimestamp:  gym.RaidBattleTimestamp.ValueOrZero(),
+		RaidEndTimestamp:     gym.RaidEndTimestamp.ValueOrZero(),
+		TeamId:               int8(gym.TeamId.ValueOrZero()),
+		AvailableSlots:       availableSlots,
+		ExRaidEligible:       gym.ExRaidEligible.ValueOrZero() != 0,
+		InBattle:             gym.InBattle.ValueOrZero() != 0,
+		PowerUpLevel:         int8(gym.PowerUpLevel.ValueOrZero()),
 	}
 	fortTreeMutex.Unlock()
 }
@@ -133,3 +294,62 @@ func addGymToTree(gym *Gym) {
 	fortTree.Insert([2]float64{gym.Lon, gym.Lat}, [2]float64{gym.Lon, gym.Lat}, gym.Id)
 	fortTreeMutex.Unlock()
 }
+
+// fortScanArea returns the bounding box to search for a scan given either as a bounding box
+// or as a fence. A fence is also returned for the forts found to be checked against.
+func fortScanArea(minLocation, maxLocation geo.Location, fence []geo.ApiLocation) (geo.Location, geo.Location, *geo.Geofence) {
+	if len(fence) == 0 {
+		return minLocation, maxLocation, nil
+	}
+	locations := make([]geo.Location, len(fence))
+	for i, location := range fence {
+		locations[i] = location.ToLocation()
+	}
+	geofence := geo.NewPolygon(locations)
+	bbox := geofence.GetBoundingBox()
+	return geo.Location{Latitude: bbox.MinimumLatitude, Longitude: bbox.MinimumLongitude},
+		geo.Location{Latitude: bbox.MaximumLatitude, Longitude: bbox.MaximumLongitude},
+		geofence
+}
+
+// scanFortTree searches the fort tree within the area, or the fence if given, calling match
+// for each fort found. match adds the keys to return for the fort, and the scan stops once
+// limit keys have been found (a limit of 0 is unlimited).
+func scanFortTree[K any](scanName string, minLocation, maxLocation geo.Location, fence []geo.ApiLocation, limit int,
+	match func(fortId string, fortLookup *FortLookup, now int64, add func(K))) []K {
+	start := time.Now()
+	now := start.Unix()
+
+	minLocation, maxLocation, geofence := fortScanArea(minLocation, maxLocation, fence)
+
+	fortsExamined := 0
+	var returnKeys []K
+	add := func(key K) {
+		returnKeys = append(returnKeys, key)
+	}
+
+	fortTreeMutex.RLock()
+	fortTree.Search([2]float64{minLocation.Longitude, minLocation.Latitude}, [2]float64{maxLocation.Longitude, maxLocation.Latitude},
+		func(min, max [2]float64, fortId string) bool {
+			fortLookup, found := fortLookupCache[fortId]
+			if !found {
+				return true
+			}
+			if geofence != nil && !geofence.Contains(geo.Location{Latitude: min[1], Longitude: min[0]}) {
+				return true
+			}
+			fortsExamined++
+
+			match(fortId, &fortLookup, now, add)
+			if limit > 0 && len(returnKeys) >= limit {
+				log.Infof("%s - result would exceed maximum size (%d), stopping scan", scanName, limit)
+				returnKeys = returnKeys[:limit]
+				return false
+			}
+			return true
+		})
+	fortTreeMutex.RUnlock()
+
+	log.Infof("%s - scan time %s, %d forts scanned, %d returned", scanName, time.Since(start), fortsExamined, len(returnKeys))
+	return returnKeys
+}
@@ -114,6 +114,11 @@ func MatchStatsGeofence(lat, lon float64) []geo.AreaName {
 	return geo.MatchGeofencesRtree(statsTree, lat, lon)
 }
 
+// StatsGeofenceBounds returns the bounding box of the named areas, or false if there are none
+func StatsGeofenceBounds(areaNames []geo.AreaName) (geo.BoundingBox, bool) {
+	return geo.AreaBoundsRtree(statsTree, areaNames)
+}
+
 func MatchNestGeofence(lat, lon float64) []geo.AreaName {
 	return geo.MatchGeofencesRtree(nestTree, lat, lon)
 }
@@ -21,45 +21,45 @@ import (
 // Gym struct.
 // REMINDER! Keep hasChangesGym updated after making changes
 type Gym struct {
-	Id                     string      `db:"id"`
-	Lat                    float64     `db:"lat"`
-	Lon                    float64     `db:"lon"`
-	Name                   null.String `db:"name"`
-	Url                    null.String `db:"url"`
-	LastModifiedTimestamp  null.Int    `db:"last_modified_timestamp"`
-	RaidEndTimestamp       null.Int    `db:"raid_end_timestamp"`
-	RaidSpawnTimestamp     null.Int    `db:"raid_spawn_timestamp"`
-	RaidBattleTimestamp    null.Int    `db:"raid_battle_timestamp"`
-	Updated                int64       `db:"updated"`
-	RaidPokemonId          null.Int    `db:"raid_pokemon_id"`
-	GuardingPokemonId      null.Int    `db:"guarding_pokemon_id"`
-	GuardingPokemonDisplay null.String `db:"guarding_pokemon_display"`
-	AvailableSlots         null.Int    `db:"available_slots"`
-	TeamId                 null.Int    `db:"team_id"`
-	RaidLevel              null.Int    `db:"raid_level"`
-	Enabled                null.Int    `db:"enabled"`
-	ExRaidEligible         null.Int    `db:"ex_raid_eligible"`
-	InBattle               null.Int    `db:"in_battle"`
-	RaidPokemonMove1       null.Int    `db:"raid_pokemon_move_1"`
-	RaidPokemonMove2       null.Int    `db:"raid_pokemon_move_2"`
-	RaidPokemonForm        null.Int    `db:"raid_pokemon_form"`
-	RaidPokemonAlignment   null.Int    `db:"raid_pokemon_alignment"`
-	RaidPokemonCp          null.Int    `db:"raid_pokemon_cp"`
-	RaidIsExclusive        null.Int    `db:"raid_is_exclusive"`
-	CellId                 null.Int    `db:"cell_id"`
-	Deleted                bool        `db:"deleted"`
-	TotalCp                null.Int    `db:"total_cp"`
-	FirstSeenTimestamp     int64       `db:"first_seen_timestamp"`
-	RaidPokemonGender      null.Int    `db:"raid_pokemon_gender"`
-	SponsorId              null.Int    `db:"sponsor_id"`
-	PartnerId              null.String `db:"partner_id"`
-	RaidPokemonCostume     null.Int    `db:"raid_pokemon_costume"`
-	RaidPokemonEvolution   null.Int    `db:"raid_pokemon_evolution"`
-	ArScanEligible         null.Int    `db:"ar_scan_eligible"`
-	PowerUpLevel           null.Int    `db:"power_up_level"`
-	PowerUpPoints          null.Int    `db:"power_up_points"`
-	PowerUpEndTimestamp    null.Int    `db:"power_up_end_timestamp"`
-	Description            null.String `db:"description"`
+	Id                     string      `db:"id" json:"id"`
+	Lat                    float64     `db:"lat" json:"lat"`
+	Lon                    float64     `db:"lon" json:"lon"`
+	Name                   null.String `db:"name" json:"name"`
+	Url                    null.String `db:"url" json:"url"`
+	LastModifiedTimestamp  null.Int    `db:"last_modified_timestamp" json:"last_modified_timestamp"`
+	RaidEndTimestamp       null.Int    `db:"raid_end_timestamp" json:"raid_end_timestamp"`
+	RaidSpawnTimestamp     null.Int    `db:"raid_spawn_timestamp" json:"raid_spawn_timestamp"`
+	RaidBattleTimestamp    null.Int    `db:"raid_battle_timestamp" json:"raid_battle_timestamp"`
+	Updated                int64       `db:"updated" json:"updated"`
+	RaidPokemonId          null.Int    `db:"raid_pokemon_id" json:"raid_pokemon_id"`
+	GuardingPokemonId      null.Int    `db:"guarding_pokemon_id" json:"guarding_pokemon_id"`
+	GuardingPokemonDisplay null.String `db:"guarding_pokemon_display" json:"guarding_pokemon_display"`
+	AvailableSlots         null.Int    `db:"available_slots" json:"available_slots"`
+	TeamId                 null.Int    `db:"team_id" json:"team_id"`
+	RaidLevel              null.Int    `db:"raid_level" json:"raid_level"`
+	Enabled                null.Int    `db:"enabled" json:"enabled"`
+	ExRaidEligible         null.Int    `db:"ex_raid_eligible" json:"ex_raid_eligible"`
+	InBattle               null.Int    `db:"in_battle" json:"in_battle"`
+	RaidPokemonMove1       null.Int    `db:"raid_pokemon_move_1" json:"raid_pokemon_move_1"`
+	RaidPokemonMove2       null.Int    `db:"raid_pokemon_move_2" json:"raid_pokemon_move_2"`
+	RaidPokemonForm        null.Int    `db:"raid_pokemon_form" json:"raid_pokemon_form"`
+	RaidPokemonAlignment   null.Int    `db:"raid_pokemon_alignment" json:"raid_pokemon_alignment"`
+	RaidPokemonCp          null.Int    `db:"raid_pokemon_cp" json:"raid_pokemon_cp"`
+	RaidIsExclusive        null.Int    `db:"raid_is_exclusive" json:"raid_is_exclusive"`
+	CellId                 null.Int    `db:"cell_id" json:"cell_id"`
+	Deleted                bool        `db:"deleted" json:"deleted"`
+	TotalCp                null.Int    `db:"total_cp" json:"total_cp"`
+	FirstSeenTimestamp     int64       `db:"first_seen_timestamp" json:"first_seen_timestamp"`
+	RaidPokemonGender      null.Int    `db:"raid_pokemon_gender" json:"raid_pokemon_gender"`
+	SponsorId              null.Int    `db:"sponsor_id" json:"sponsor_id"`
+	PartnerId              null.String `db:"partner_id" json:"partner_id"`
+	RaidPokemonCostume     null.Int    `db:"raid_pokemon_costume" json:"raid_pokemon_costume"`
+	RaidPokemonEvolution   null.Int    `db:"raid_pokemon_evolution" json:"raid_pokemon_evolution"`
+	ArScanEligible         null.Int    `db:"ar_scan_eligible" json:"ar_scan_eligible"`
+	PowerUpLevel           null.Int    `db:"power_up_level" json:"power_up_level"`
+	PowerUpPoints          null.Int    `db:"power_up_points" json:"power_up_points"`
+	PowerUpEndTimestamp    null.Int    `db:"power_up_end_timestamp" json:"power_up_end_timestamp"`
+	Description            null.String `db:"description" json:"description"`
 	//`id` varchar(35) NOT NULL,
 	//`lat` double(18,14) NOT NULL,
 	//`lon` double(18,14) NOT NULL,
@@ -293,7 +293,7 @@ func (gym *Gym) updateGymFromGymInfoOutProto(gymData *pogo.GymGetInfoOutProto) *
 	gym.Lat = gymData.GymStatusAndDefenders.PokemonFortProto.Latitude
 	gym.Lon = gymData.GymStatusAndDefenders.PokemonFortProto.Longitude
 
-	// This will have gym defenders in it...
+	// Defenders are stored separately, see saveGymDefenders
 	if len(gymData.Url) > 0 {
 		gym.Url = null.StringFrom(gymData.Url)
 	}
@@ -495,14 +495,20 @@ func createGymWebhooks(oldGym *Gym, gym *Gym) {
 
 }
 
-func saveGymRecord(ctx context.Context, db db.DbDetails, gym *Gym) {
+// saveGymRecordAsAtTime saves a gym as seen at the given time, refusing the update if the
+// stored gym was seen more recently. Returns false if the update was refused.
+func saveGymRecordAsAtTime(ctx context.Context, db db.DbDetails, gym *Gym, now int64) bool {
 	oldGym, _ := getGymRecord(ctx, db, gym.Id)
 
-	now := time.Now().Unix()
+	if oldGym != nil && oldGym.Updated > now {
+		statsCollector.IncStaleUpdates("gym")
+		log.Debugf("Gym %s: ignored update as at %d, older than stored %d", gym.Id, now, oldGym.Updated)
+		return false
+	}
 	if oldGym != nil && !hasChangesGym(oldGym, gym) {
 		if oldGym.Updated > now-900 {
 			// if a gym is unchanged, but we did see it again after 15 minutes, then save again
-			return
+			return true
 		}
 	}
 
@@ -516,7 +522,7 @@ func saveGymRecord(ctx context.Context, db db.DbDetails, gym *Gym) {
 		statsCollector.IncDbQuery("insert gym", err)
 		if err != nil {
 			log.Errorf("insert gym: %s", err)
-			return
+			return true
 		}
 
 		_, _ = res, err
@@ -569,11 +575,15 @@ func saveGymRecord(ctx context.Context, db db.DbDetails, gym *Gym) {
 	}
 
 	gymCache.Set(gym.Id, *gym, ttlcache.DefaultTTL)
+	if config.Config.TestFortInMemory {
+		fortRtreeUpdateGymOnSave(gym)
+	}
 	createGymWebhooks(oldGym, gym)
 	createGymFortWebhooks(oldGym, gym)
 
 	areas := MatchStatsGeofence(gym.Lat, gym.Lon)
 	updateRaidStats(oldGym, gym, areas)
+	return true
 }
 
 func updateGymGetMapFortCache(gym *Gym, skipName bool) {
@@ -586,7 +596,7 @@ func updateGymGetMapFortCache(gym *Gym, skipName bool) {
 	}
 }
 
-func UpdateGymRecordWithFortDetailsOutProto(ctx context.Context, db db.DbDetails, fort *pogo.FortDetailsOutProto) string {
+func UpdateGymRecordWithFortDetailsOutProto(ctx context.Context, db db.DbDetails, fort *pogo.FortDetailsOutProto, timestampMs int64) string {
 	gymMutex, _ := gymStripedMutex.GetLock(fort.Id)
 	gymMutex.Lock()
 	defer gymMutex.Unlock()
@@ -602,12 +612,12 @@ func UpdateGymRecordWithFortDetailsOutProto(ctx context.Context, db db.DbDetails
 	gym.updateGymFromFortProto(fort)
 
 	updateGymGetMapFortCache(gym, true)
-	saveGymRecord(ctx, db, gym)
+	saveGymRecordAsAtTime(ctx, db, gym, timestampMs/1000)
 
 	return fmt.Sprintf("%s %s", gym.Id, gym.Name.ValueOrZero())
 }
 
-func UpdateGymRecordWithGymInfoProto(ctx context.Context, db db.DbDetails, gymInfo *pogo.GymGetInfoOutProto) string {
+func UpdateGymRecordWithGymInfoProto(ctx context.Context, db db.DbDetails, gymInfo *pogo.GymGetInfoOutProto, timestampMs int64) string {
 	gymMutex, _ := gymStripedMutex.GetLock(gymInfo.GymStatusAndDefenders.PokemonFortProto.FortId)
 	gymMutex.Lock()
 	defer gymMutex.Unlock()
@@ -623,11 +633,17 @@ func UpdateGymRecordWithGymInfoProto(ctx context.Context, db db.DbDetails, gymIn
 	gym.updateGymFromGymInfoOutProto(gymInfo)
 
 	updateGymGetMapFortCache(gym, true)
-	saveGymRecord(ctx, db, gym)
+	if !saveGymRecordAsAtTime(ctx, db, gym, timestampMs/1000) {
+		// the defenders were seen at the same time, so are stale along with the gym
+		return fmt.Sprintf("%s %s", gym.Id, gym.Name.ValueOrZero())
+	}
+
+	defenders := gymDefendersFromProto(gym.Id, gymInfo.GetGymStatusAndDefenders().GetGymDefender(), timestampMs/1000)
+	saveGymDefenders(ctx, db, gym, defenders, timestampMs/1000)
 	return fmt.Sprintf("%s %s", gym.Id, gym.Name.ValueOrZero())
 }
 
-func UpdateGymRecordWithGetMapFortsOutProto(ctx context.Context, db db.DbDetails, mapFort *pogo.GetMapFortsOutProto_FortProto) (bool, string) {
+func UpdateGymRecordWithGetMapFortsOutProto(ctx context.Context, db db.DbDetails, mapFort *pogo.GetMapFortsOutProto_FortProto, timestampMs int64) (bool, string) {
 	gymMutex, _ := gymStripedMutex.GetLock(mapFort.Id)
 	gymMutex.Lock()
 	defer gymMutex.Unlock()
@@ -643,6 +659,6 @@ func UpdateGymRecordWithGetMapFortsOutProto(ctx context.Context, db db.DbDetails
 	}
 
 	gym.updateGymFromGetMapFortsOutProto(mapFort, false)
-	saveGymRecord(ctx, db, gym)
+	saveGymRecordAsAtTime(ctx, db, gym, timestampMs/1000)
 	return true, fmt.Sprintf("%s %s", gym.Id, gym.Name.ValueOrZero())
 }
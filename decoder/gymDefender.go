@@ -0,0 +1,185 @@
+package decoder
+
+import (
+	"context"
+
+	"github.com/jellydator/ttlcache/v3"
+	log "github.com/sirupsen/logrus"
+
+	"golbat/db"
+	"golbat/pogo"
+	"golbat/webhooks"
+)
+
+// GymDefender is a pokemon deployed in a gym, as last seen in GYM_GET_INFO.
+// REMINDER! Keep hasChangesGymDefenders updated after making changes
+type GymDefender struct {
+	GymId             string  `db:"gym_id" json:"-"`
+	Slot              int16   `db:"slot" json:"slot"`
+	PokemonId         int16   `db:"pokemon_id" json:"pokemon_id"`
+	Form              int16   `db:"form" json:"form"`
+	Costume           int16   `db:"costume" json:"costume"`
+	Gender            int16   `db:"gender" json:"gender"`
+	Shiny             bool    `db:"shiny" json:"shiny"`
+	CpNow             int32   `db:"cp_now" json:"cp_now"`
+	CpWhenDeployed    int32   `db:"cp_when_deployed" json:"cp_when_deployed"`
+	Motivation        float64 `db:"motivation" json:"motivation"`
+	DeployedTimestamp int64   `db:"deployed_timestamp" json:"deployed_timestamp"`
+	TrainerName       string  `db:"trainer_name" json:"trainer_name"`
+	TrainerLevel      int32   `db:"trainer_level" json:"trainer_level"`
+	TimesFed          int32   `db:"times_fed" json:"times_fed"`
+	BattlesWon        int32   `db:"battles_won" json:"battles_won"`
+	BattlesLost       int32   `db:"battles_lost" json:"battles_lost"`
+	Updated           int64   `db:"updated" json:"updated"`
+}
+
+// ApiGymResult is a gym along with its defenders
+type ApiGymResult struct {
+	*Gym
+	Defenders []GymDefender `json:"defenders"`
+}
+
+func getGymDefenders(ctx context.Context, db db.DbDetails, gymId string) ([]GymDefender, error) {
+	inMemoryDefenders := gymDefenderCache.Get(gymId)
+	if inMemoryDefenders != nil {
+		return inMemoryDefenders.Value(), nil
+	}
+
+	defenders := []GymDefender{}
+	err := db.GeneralDb.SelectContext(ctx, &defenders,
+		"SELECT gym_id, slot, pokemon_id, form, costume, gender, shiny, cp_now, cp_when_deployed, motivation, "+
+			"deployed_timestamp, trainer_name, trainer_level, times_fed, battles_won, battles_lost, updated "+
+			"FROM gym_defender WHERE gym_id = ? ORDER BY slot", gymId)
+	statsCollector.IncDbQuery("select gym_defender", err)
+	if err != nil {
+		return nil, err
+	}
+
+	gymDefenderCache.Set(gymId, defenders, ttlcache.DefaultTTL)
+	return defenders, nil
+}
+
+func gymDefendersFromProto(gymId string, gymDefenders []*pogo.GymDefenderProto, now int64) []GymDefender {
+	defenders := make([]GymDefender, 0, len(gymDefenders))
+	for i, gymDefender := range gymDefenders {
+		motivatedPokemon := gymDefender.GetMotivatedPokemon()
+		pokemon := motivatedPokemon.GetPokemon()
+		display := pokemon.GetPokemonDisplay()
+		trainer := gymDefender.GetTrainerPublicProfile()
+		totals := gymDefender.GetDeploymentTotals()
+
+		defenders = append(defenders, GymDefender{
+			GymId:             gymId,
+			Slot:              int16(i),
+			PokemonId:         int16(pokemon.GetPokemonId()),
+			Form:              int16(display.GetForm()),
+			Costume:           int16(display.GetCostume()),
+			Gender:            int16(display.GetGender()),
+			Shiny:             display.GetShiny(),
+			CpNow:             motivatedPokemon.GetCpNow(),
+			CpWhenDeployed:    motivatedPokemon.GetCpWhenDeployed(),
+			Motivation:        motivatedPokemon.GetMotivationNow(),
+			DeployedTimestamp: motivatedPokemon.GetDeployMs() / 1000,
+			TrainerName:       trainer.GetName(),
+			TrainerLevel:      trainer.GetLevel(),
+			TimesFed:          totals.GetTimesFed(),
+			BattlesWon:        totals.GetBattlesWon(),
+			BattlesLost:       totals.GetBattlesLost(),
+			Updated:           now,
+		})
+	}
+	return defenders
+}
+
+// hasChangesGymDefenders compares two defender lists. Motivation is not compared, as it only
+// moves with CP.
+func hasChangesGymDefenders(old []GymDefender, new []GymDefender) bool {
+	if len(old) != len(new) {
+		return true
+	}
+	for i := range old {
+		if old[i].PokemonId != new[i].PokemonId ||
+			old[i].Form != new[i].Form ||
+			old[i].Costume != new[i].Costume ||
+			old[i].CpNow != new[i].CpNow ||
+			old[i].DeployedTimestamp != new[i].DeployedTimestamp ||
+			old[i].TrainerName != new[i].TrainerName ||
+			old[i].TimesFed != new[i].TimesFed ||
+			old[i].BattlesWon != new[i].BattlesWon ||
+			old[i].BattlesLost != new[i].BattlesLost {
+			return true
+		}
+	}
+	return false
+}
+
+// saveGymDefenders replaces the stored defenders of a gym with those seen at the given
+// time. The gym mutex must be held.
+func saveGymDefenders(ctx context.Context, db db.DbDetails, gym *Gym, defenders []GymDefender, now int64) {
+	oldDefenders, err := getGymDefenders(ctx, db, gym.Id)
+	if err != nil {
+		log.Errorf("select gym_defender: %s", err)
+		return
+	}
+	if !hasChangesGymDefenders(oldDefenders, defenders) {
+		return
+	}
+
+	if len(defenders) > 0 {
+		_, err = db.GeneralDb.NamedExecContext(ctx,
+			"INSERT INTO gym_defender (gym_id, slot, pokemon_id, form, costume, gender, shiny, cp_now, cp_when_deployed, "+
+				"motivation, deployed_timestamp, trainer_name, trainer_level, times_fed, battles_won, battles_lost, updated) "+
+				"VALUES (:gym_id, :slot, :pokemon_id, :form, :costume, :gender, :shiny, :cp_now, :cp_when_deployed, "+
+				":motivation, :deployed_timestamp, :trainer_name, :trainer_level, :times_fed, :battles_won, :battles_lost, :updated) "+
+				"ON DUPLICATE KEY UPDATE "+
+				"pokemon_id = VALUES(pokemon_id), form = VALUES(form), costume = VALUES(costume), gender = VALUES(gender), "+
+				"shiny = VALUES(shiny), cp_now = VALUES(cp_now), cp_when_deployed = VALUES(cp_when_deployed), "+
+				"motivation = VALUES(motivation), deployed_timestamp = VALUES(deployed_timestamp), "+
+				"trainer_name = VALUES(trainer_name), trainer_level = VALUES(trainer_level), times_fed = VALUES(times_fed), "+
+				"battles_won = VALUES(battles_won), battles_lost = VALUES(battles_lost), updated = VALUES(updated)",
+			defenders)
+		statsCollector.IncDbQuery("insert gym_defender", err)
+		if err != nil {
+			log.Errorf("insert gym_defender: %s", err)
+			return
+		}
+	}
+
+	// Defenders which have been knocked out leave the higher slots empty
+	_, err = db.GeneralDb.ExecContext(ctx, "DELETE FROM gym_defender WHERE gym_id = ? AND slot >= ?", gym.Id, len(defenders))
+	statsCollector.IncDbQuery("delete gym_defender", err)
+	if err != nil {
+		log.Errorf("delete gym_defender: %s", err)
+		return
+	}
+
+	gymDefenderCache.Set(gym.Id, defenders, ttlcache.DefaultTTL)
+	createGymDefendersWebhook(gym, defenders, now)
+}
+
+func createGymDefendersWebhook(gym *Gym, defenders []GymDefender, now int64) {
+	defendersHook := map[string]interface{}{
+		"gym_id":    gym.Id,
+		"gym_name":  gym.Name.ValueOrZero(),
+		"latitude":  gym.Lat,
+		"longitude": gym.Lon,
+		"team_id":   gym.TeamId.ValueOrZero(),
+		"defenders": defenders,
+		"updated":   now,
+	}
+	areas := MatchStatsGeofence(gym.Lat, gym.Lon)
+	webhooksSender.AddMessage(webhooks.GymDefenders, defendersHook, areas)
+}
+
+// GetGymWithDefenders returns a gym and its last seen defenders, or nil if the gym is unknown
+func GetGymWithDefenders(ctx context.Context, db db.DbDetails, gymId string) (*ApiGymResult, error) {
+	gym, err := getGymRecord(ctx, db, gymId)
+	if err != nil || gym == nil {
+		return nil, err
+	}
+	defenders, err := getGymDefenders(ctx, db, gymId)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiGymResult{Gym: gym, Defenders: defenders}, nil
+}
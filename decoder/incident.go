@@ -9,6 +9,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	null "gopkg.in/guregu/null.v4"
 
+	"golbat/config"
 	"golbat/db"
 	"golbat/pogo"
 	"golbat/webhooks"
@@ -17,21 +18,21 @@ import (
 // Incident struct.
 // REMINDER! Keep hasChangesIncident updated after making changes
 type Incident struct {
-	Id             string   `db:"id"`
-	PokestopId     string   `db:"pokestop_id"`
-	StartTime      int64    `db:"start"`
-	ExpirationTime int64    `db:"expiration"`
-	DisplayType    int16    `db:"display_type"`
-	Style          int16    `db:"style"`
-	Character      int16    `db:"character"`
-	Updated        int64    `db:"updated"`
-	Confirmed      bool     `db:"confirmed"`
-	Slot1PokemonId null.Int `db:"slot_1_pokemon_id"`
-	Slot1Form      null.Int `db:"slot_1_form"`
-	Slot2PokemonId null.Int `db:"slot_2_pokemon_id"`
-	Slot2Form      null.Int `db:"slot_2_form"`
-	Slot3PokemonId null.Int `db:"slot_3_pokemon_id"`
-	Slot3Form      null.Int `db:"slot_3_form"`
+	Id             string   `db:"id" json:"id"`
+	PokestopId     string   `db:"pokestop_id" json:"pokestop_id"`
+	StartTime      int64    `db:"start" json:"start"`
+	ExpirationTime int64    `db:"expiration" json:"expiration"`
+	DisplayType    int16    `db:"display_type" json:"display_type"`
+	Style          int16    `db:"style" json:"style"`
+	Character      int16    `db:"character" json:"character"`
+	Updated        int64    `db:"updated" json:"updated"`
+	Confirmed      bool     `db:"confirmed" json:"confirmed"`
+	Slot1PokemonId null.Int `db:"slot_1_pokemon_id" json:"slot_1_pokemon_id"`
+	Slot1Form      null.Int `db:"slot_1_form" json:"slot_1_form"`
+	Slot2PokemonId null.Int `db:"slot_2_pokemon_id" json:"slot_2_pokemon_id"`
+	Slot2Form      null.Int `db:"slot_2_form" json:"slot_2_form"`
+	Slot3PokemonId null.Int `db:"slot_3_pokemon_id" json:"slot_3_pokemon_id"`
+	Slot3Form      null.Int `db:"slot_3_form" json:"slot_3_form"`
 }
 
 type webhookLineup struct {
@@ -142,6 +143,9 @@ func saveIncidentRecord(ctx context.Context, db db.DbDetails, incident *Incident
 	}
 
 	incidentCache.Set(incident.Id, *incident, ttlcache.DefaultTTL)
+	if config.Config.TestFortInMemory {
+		fortRtreeUpdateIncident(incident)
+	}
 	createIncidentWebhooks(ctx, db, oldIncident, incident)
 
 	stop, _ := GetPokestopRecord(ctx, db, incident.PokestopId)
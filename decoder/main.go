@@ -23,13 +23,15 @@ import (
 )
 
 type RawFortData struct {
-	Cell uint64
-	Data *pogo.PokemonFortProto
+	Cell      uint64
+	Data      *pogo.PokemonFortProto
+	Timestamp uint64
 }
 
 type RawStationData struct {
-	Cell uint64
-	Data *pogo.StationProto
+	Cell      uint64
+	Data      *pogo.StationProto
+	Timestamp uint64
 }
 
 type RawWildPokemonData struct {
@@ -39,18 +41,21 @@ type RawWildPokemonData struct {
 }
 
 type RawNearbyPokemonData struct {
-	Cell uint64
-	Data *pogo.NearbyPokemonProto
+	Cell      uint64
+	Data      *pogo.NearbyPokemonProto
+	Timestamp uint64
 }
 
 type RawMapPokemonData struct {
-	Cell uint64
-	Data *pogo.MapPokemonProto
+	Cell      uint64
+	Data      *pogo.MapPokemonProto
+	Timestamp uint64
 }
 
 type RawClientWeatherData struct {
-	Cell int64
-	Data *pogo.ClientWeatherProto
+	Cell      int64
+	Data      *pogo.ClientWeatherProto
+	Timestamp uint64
 }
 
 type webhooksSenderInterface interface {
@@ -61,6 +66,7 @@ var webhooksSender webhooksSenderInterface
 var statsCollector stats_collector.StatsCollector
 var pokestopCache *ttlcache.Cache[string, Pokestop]
 var gymCache *ttlcache.Cache[string, Gym]
+var gymDefenderCache *ttlcache.Cache[string, []GymDefender]
 var stationCache *ttlcache.Cache[string, Station]
 var weatherCache *ttlcache.Cache[int64, Weather]
 var s2CellCache *ttlcache.Cache[uint64, S2Cell]
@@ -71,6 +77,7 @@ var playerCache *ttlcache.Cache[string, Player]
 var routeCache *ttlcache.Cache[string, Route]
 var diskEncounterCache *ttlcache.Cache[string, *pogo.DiskEncounterOutProto]
 var getMapFortsCache *ttlcache.Cache[string, *pogo.GetMapFortsOutProto_FortProto]
+var encounterLeaseCache *ttlcache.Cache[uint64, string]
 
 var gymStripedMutex = stripedmutex.New(128)
 var pokestopStripedMutex = stripedmutex.New(128)
@@ -79,7 +86,9 @@ var incidentStripedMutex = stripedmutex.New(128)
 var pokemonStripedMutex = stripedmutex.New(1024)
 var weatherStripedMutex = stripedmutex.New(128)
 var s2cellStripedMutex = stripedmutex.New(1024)
+var spawnpointStripedMutex = stripedmutex.New(1024)
 var routeStripedMutex = stripedmutex.New(128)
+var accountStripedMutex = stripedmutex.New(128)
 
 var s2CellLookup = sync.Map{}
 
@@ -107,6 +116,11 @@ func initDataCache() {
 	)
 	go gymCache.Start()
 
+	gymDefenderCache = ttlcache.New[string, []GymDefender](
+		ttlcache.WithTTL[string, []GymDefender](60 * time.Minute),
+	)
+	go gymDefenderCache.Start()
+
 	stationCache = ttlcache.New[string, Station](
 		ttlcache.WithTTL[string, Station](60 * time.Minute),
 	)
@@ -127,6 +141,11 @@ func initDataCache() {
 	)
 	go spawnpointCache.Start()
 
+	spawnpointSightingCache = ttlcache.New[uint64, []spawnpointSighting](
+		ttlcache.WithTTL[uint64, []spawnpointSighting](spawnGoneMaxMinutes * time.Minute),
+	)
+	go spawnpointSightingCache.Start()
+
 	pokemonCache = ttlcache.New[string, Pokemon](
 		ttlcache.WithTTL[string, Pokemon](60*time.Minute),
 		ttlcache.WithDisableTouchOnHit[string, Pokemon](), // Pokemon will last 60 mins from when we first see them not last see them
@@ -145,6 +164,11 @@ func initDataCache() {
 	)
 	go playerCache.Start()
 
+	accountCache = ttlcache.New[string, Account](
+		ttlcache.WithTTL[string, Account](24 * time.Hour),
+	)
+	go accountCache.Start()
+
 	diskEncounterCache = ttlcache.New[string, *pogo.DiskEncounterOutProto](
 		ttlcache.WithTTL[string, *pogo.DiskEncounterOutProto](10*time.Minute),
 		ttlcache.WithDisableTouchOnHit[string, *pogo.DiskEncounterOutProto](),
@@ -161,6 +185,13 @@ func initDataCache() {
 		ttlcache.WithTTL[string, Route](60 * time.Minute),
 	)
 	go routeCache.Start()
+
+	// leases are set with the configured ttl, as config is not yet loaded here
+	encounterLeaseCache = ttlcache.New[uint64, string](
+		ttlcache.WithTTL[uint64, string](60*time.Second),
+		ttlcache.WithDisableTouchOnHit[uint64, string](),
+	)
+	go encounterLeaseCache.Start()
 }
 
 func InitialiseOhbem() {
@@ -256,7 +287,11 @@ func UpdateFortBatch(ctx context.Context, db db.DbDetails, scanParameters ScanPa
 				pokestop = &Pokestop{}
 			}
 			pokestop.updatePokestopFromFort(fort.Data, fort.Cell)
-			savePokestopRecord(ctx, db, pokestop)
+			if !savePokestopRecordAsAtTime(ctx, db, pokestop, int64(fort.Timestamp/1000)) {
+				// the incidents were seen at the same time, so are stale along with the pokestop
+				pokestopMutex.Unlock()
+				continue
+			}
 
 			incidents := fort.Data.PokestopDisplays
 			if incidents == nil && fort.Data.PokestopDisplay != nil {
@@ -304,7 +339,7 @@ func UpdateFortBatch(ctx context.Context, db db.DbDetails, scanParameters ScanPa
 			}
 
 			gym.updateGymFromFort(fort.Data, fort.Cell)
-			saveGymRecord(ctx, db, gym)
+			saveGymRecordAsAtTime(ctx, db, gym, int64(fort.Timestamp/1000))
 			gymMutex.Unlock()
 		}
 	}
@@ -325,7 +360,7 @@ func UpdateStationBatch(ctx context.Context, db db.DbDetails, scanParameters Sca
 			station = &Station{}
 		}
 		station.updateFromStationProto(stationProto.Data, stationProto.Cell)
-		saveStationRecord(ctx, db, station)
+		saveStationRecordAsAtTime(ctx, db, station, int64(stationProto.Timestamp/1000))
 		stationMutex.Unlock()
 	}
 }
@@ -383,7 +418,7 @@ func UpdatePokemonBatch(ctx context.Context, db db.DbDetails, scanParameters Sca
 				log.Printf("getOrCreatePokemonRecord: %s", err)
 			} else {
 				pokemon.updateFromNearby(ctx, db, nearby.Data, int64(nearby.Cell), username)
-				savePokemonRecord(ctx, db, pokemon)
+				savePokemonRecordAsAtTime(ctx, db, pokemon, int64(nearby.Timestamp/1000))
 			}
 
 			pokemonMutex.Unlock()
@@ -407,7 +442,7 @@ func UpdatePokemonBatch(ctx context.Context, db db.DbDetails, scanParameters Sca
 				pokemon.updatePokemonFromDiskEncounterProto(ctx, db, diskEncounter, username)
 				log.Infof("Processed stored disk encounter")
 			}
-			savePokemonRecord(ctx, db, pokemon)
+			savePokemonRecordAsAtTime(ctx, db, pokemon, int64(mapPokemon.Timestamp/1000))
 		}
 		pokemonMutex.Unlock()
 	}
@@ -426,7 +461,7 @@ func UpdateClientWeatherBatch(ctx context.Context, db db.DbDetails, p []RawClien
 				weather = &Weather{}
 			}
 			weather.updateWeatherFromClientWeatherProto(weatherProto.Data)
-			saveWeatherRecord(ctx, db, weather)
+			saveWeatherRecordAsAtTime(ctx, db, weather, int64(weatherProto.Timestamp/1000))
 		}
 		weatherMutex.Unlock()
 	}
@@ -473,6 +508,10 @@ func ClearRemovedForts(ctx context.Context, dbDetails db.DbDetails, mapCells []u
 					gymsDone = true
 					for _, gymId := range gymIds {
 						gymCache.Delete(gymId)
+						gymDefenderCache.Delete(gymId)
+					}
+					if err := db.ClearGymDefenders(ctx, dbDetails, gymIds); err != nil {
+						log.Errorf("ClearRemovedForts - Unable to clear defenders of old gyms '%v': %s", gymIds, err)
 					}
 					log.Infof("ClearRemovedForts - Cleared old Gym(s) in cell %d: %v", cellId, gymIds)
 					CreateFortWebhooks(ctx, dbDetails, gymIds, GYM, REMOVAL)
@@ -504,7 +543,30 @@ func ClearRemovedForts(ctx context.Context, dbDetails db.DbDetails, mapCells []u
 			}
 		}
 
-		if gymsDone && stopsDone {
+		var stationsDone = false
+		stationIds, stationsErr := db.FindOldStations(ctx, dbDetails, int64(cellId))
+		if stationsErr != nil {
+			log.Errorf("ClearRemovedForts - Unable to clear old stations: %s", stationsErr)
+		} else {
+			if stationIds == nil {
+				stationsDone = true
+			} else {
+				// stations not seen for 60 minutes are marked inactive rather than removed
+				stationsErr2 := db.ClearOldStations(ctx, dbDetails, stationIds)
+				if stationsErr2 != nil {
+					log.Errorf("ClearRemovedForts - Unable to clear old stations '%v': %s", stationIds, stationsErr2)
+				} else {
+					stationsDone = true
+					for _, stationId := range stationIds {
+						stationCache.Delete(stationId)
+					}
+					log.Infof("ClearRemovedForts - Marked old Station(s) inactive in cell %d: %v", cellId, stationIds)
+					createInactiveStationWebhooks(ctx, dbDetails, stationIds)
+				}
+			}
+		}
+
+		if gymsDone && stopsDone && stationsDone {
 			s2CellLookup.Store(cellId, now)
 		}
 		s2cellMutex.Unlock()
@@ -224,10 +224,6 @@ func hasChangesPokemon(old *Pokemon, new *Pokemon) bool {
 		!nullFloatAlmostEqual(old.Capture3, new.Capture3, floatTolerance)
 }
 
-func savePokemonRecord(ctx context.Context, db db.DbDetails, pokemon *Pokemon) {
-	savePokemonRecordAsAtTime(ctx, db, pokemon, time.Now().Unix())
-}
-
 func savePokemonRecordAsAtTime(ctx context.Context, db db.DbDetails, pokemon *Pokemon, now int64) {
 	oldPokemon, _ := getPokemonRecord(ctx, db, pokemon.Id)
 
@@ -684,6 +680,17 @@ func (pokemon *Pokemon) updateSpawnpointInfo(ctx context.Context, db db.DbDetail
 		pokemon.ExpireTimestampVerified = true
 	} else {
 		pokemon.setUnknownTimestamp()
+		if spawnPoint != nil {
+			// the pokemon will be around for at least as long as the spawnpoint has been seen
+			// occupied, which may be beyond the default estimate
+			date := time.Unix(timestampMs/1000, 0)
+			secondOfHour := int64(date.Second() + date.Minute()*60)
+			pattern := inferSpawnPattern(spawnPoint.SeenMinutes, spawnPoint.GoneMinutes, spawnPoint.DespawnSec)
+			if remaining := estimateUnverifiedDespawn(pattern, secondOfHour); remaining > 0 &&
+				timestampMs/1000+remaining > pokemon.ExpireTimestamp.Int64 {
+				pokemon.ExpireTimestamp = null.IntFrom(timestampMs/1000 + remaining)
+			}
+		}
 	}
 }
 
@@ -1111,7 +1118,7 @@ func (pokemon *Pokemon) repopulateStatsIfNeeded(ctx context.Context, db db.DbDet
 	}
 }
 
-func UpdatePokemonRecordWithEncounterProto(ctx context.Context, db db.DbDetails, encounter *pogo.EncounterOutProto, username string) string {
+func UpdatePokemonRecordWithEncounterProto(ctx context.Context, db db.DbDetails, encounter *pogo.EncounterOutProto, username string, timestampMs int64) string {
 	if encounter.Pokemon == nil {
 		return "No encounter"
 	}
@@ -1129,7 +1136,8 @@ func UpdatePokemonRecordWithEncounterProto(ctx context.Context, db db.DbDetails,
 	}
 
 	pokemon.updatePokemonFromEncounterProto(ctx, db, encounter, username)
-	savePokemonRecord(ctx, db, pokemon)
+	savePokemonRecordAsAtTime(ctx, db, pokemon, timestampMs/1000)
+	clearEncounterLease(pokemon)
 	// updateEncounterStats() should only be called for encounters, and called
 	// even if we have the pokemon record already.
 	updateEncounterStats(pokemon)
@@ -1137,7 +1145,7 @@ func UpdatePokemonRecordWithEncounterProto(ctx context.Context, db db.DbDetails,
 	return fmt.Sprintf("%d %s Pokemon %d CP%d", encounter.Pokemon.EncounterId, encounterId, pokemon.PokemonId, encounter.Pokemon.Pokemon.Cp)
 }
 
-func UpdatePokemonRecordWithDiskEncounterProto(ctx context.Context, db db.DbDetails, encounter *pogo.DiskEncounterOutProto, username string) string {
+func UpdatePokemonRecordWithDiskEncounterProto(ctx context.Context, db db.DbDetails, encounter *pogo.DiskEncounterOutProto, username string, timestampMs int64) string {
 	if encounter.Pokemon == nil {
 		return "No encounter"
 	}
@@ -1160,7 +1168,8 @@ func UpdatePokemonRecordWithDiskEncounterProto(ctx context.Context, db db.DbDeta
 		return fmt.Sprintf("%s Disk encounter without previous GMO - Pokemon stored for later", encounterId)
 	}
 	pokemon.updatePokemonFromDiskEncounterProto(ctx, db, encounter, username)
-	savePokemonRecord(ctx, db, pokemon)
+	savePokemonRecordAsAtTime(ctx, db, pokemon, timestampMs/1000)
+	clearEncounterLease(pokemon)
 	// updateEncounterStats() should only be called for encounters, and called
 	// even if we have the pokemon record already.
 	updateEncounterStats(pokemon)
@@ -773,13 +773,19 @@ func createPokestopWebhooks(oldStop *Pokestop, stop *Pokestop) {
 	}
 }
 
-func savePokestopRecord(ctx context.Context, db db.DbDetails, pokestop *Pokestop) {
+// savePokestopRecordAsAtTime saves a pokestop as seen at the given time, refusing the update
+// if the stored pokestop was seen more recently. Returns false if the update was refused.
+func savePokestopRecordAsAtTime(ctx context.Context, db db.DbDetails, pokestop *Pokestop, now int64) bool {
 	oldPokestop, _ := GetPokestopRecord(ctx, db, pokestop.Id)
-	now := time.Now().Unix()
+	if oldPokestop != nil && oldPokestop.Updated > now {
+		statsCollector.IncStaleUpdates("pokestop")
+		log.Debugf("Pokestop %s: ignored update as at %d, older than stored %d", pokestop.Id, now, oldPokestop.Updated)
+		return false
+	}
 	if oldPokestop != nil && !hasChangesPokestop(oldPokestop, pokestop) {
 		if oldPokestop.Updated > now-900 {
 			// if a pokestop is unchanged, but we did see it again after 15 minutes, then save again
-			return
+			return true
 		}
 	}
 	pokestop.Updated = now
@@ -814,7 +820,7 @@ func savePokestopRecord(ctx context.Context, db db.DbDetails, pokestop *Pokestop
 		//log.Debugf("Insert pokestop %s %+v", pokestop.Id, pokestop)
 		if err != nil {
 			log.Errorf("insert pokestop %s: %s", pokestop.Id, err)
-			return
+			return true
 		}
 		_ = res
 	} else {
@@ -867,13 +873,17 @@ func savePokestopRecord(ctx context.Context, db db.DbDetails, pokestop *Pokestop
 		//log.Debugf("Update pokestop %s %+v", pokestop.Id, pokestop)
 		if err != nil {
 			log.Errorf("update pokestop %s: %s", pokestop.Id, err)
-			return
+			return true
 		}
 		_ = res
 	}
 	pokestopCache.Set(pokestop.Id, *pokestop, ttlcache.DefaultTTL)
+	if config.Config.TestFortInMemory {
+		fortRtreeUpdatePokestopOnSave(pokestop)
+	}
 	createPokestopWebhooks(oldPokestop, pokestop)
 	createPokestopFortWebhooks(oldPokestop, pokestop)
+	return true
 }
 
 func updatePokestopGetMapFortCache(pokestop *Pokestop) {
@@ -886,7 +896,7 @@ func updatePokestopGetMapFortCache(pokestop *Pokestop) {
 	}
 }
 
-func UpdatePokestopRecordWithFortDetailsOutProto(ctx context.Context, db db.DbDetails, fort *pogo.FortDetailsOutProto) string {
+func UpdatePokestopRecordWithFortDetailsOutProto(ctx context.Context, db db.DbDetails, fort *pogo.FortDetailsOutProto, timestampMs int64) string {
 	pokestopMutex, _ := pokestopStripedMutex.GetLock(fort.Id)
 	pokestopMutex.Lock()
 	defer pokestopMutex.Unlock()
@@ -903,11 +913,11 @@ func UpdatePokestopRecordWithFortDetailsOutProto(ctx context.Context, db db.DbDe
 	pokestop.updatePokestopFromFortDetailsProto(fort)
 
 	updatePokestopGetMapFortCache(pokestop)
-	savePokestopRecord(ctx, db, pokestop)
+	savePokestopRecordAsAtTime(ctx, db, pokestop, timestampMs/1000)
 	return fmt.Sprintf("%s %s", fort.Id, fort.Name)
 }
 
-func UpdatePokestopWithQuest(ctx context.Context, db db.DbDetails, quest *pogo.FortSearchOutProto, haveAr bool) string {
+func UpdatePokestopWithQuest(ctx context.Context, db db.DbDetails, quest *pogo.FortSearchOutProto, haveAr bool, timestampMs int64) string {
 	haveArStr := "NoAR"
 	if haveAr {
 		haveArStr = "AR"
@@ -935,7 +945,7 @@ func UpdatePokestopWithQuest(ctx context.Context, db db.DbDetails, quest *pogo.F
 	questTitle := pokestop.updatePokestopFromQuestProto(quest, haveAr)
 
 	updatePokestopGetMapFortCache(pokestop)
-	savePokestopRecord(ctx, db, pokestop)
+	savePokestopRecordAsAtTime(ctx, db, pokestop, timestampMs/1000)
 
 	areas := MatchStatsGeofence(pokestop.Lat, pokestop.Lon)
 	updateQuestStats(pokestop, haveAr, areas)
@@ -963,7 +973,7 @@ func GetQuestStatusWithGeofence(dbDetails db.DbDetails, geofence *geojson.Featur
 	return res
 }
 
-func UpdatePokestopRecordWithGetMapFortsOutProto(ctx context.Context, db db.DbDetails, mapFort *pogo.GetMapFortsOutProto_FortProto) (bool, string) {
+func UpdatePokestopRecordWithGetMapFortsOutProto(ctx context.Context, db db.DbDetails, mapFort *pogo.GetMapFortsOutProto_FortProto, timestampMs int64) (bool, string) {
 	pokestopMutex, _ := pokestopStripedMutex.GetLock(mapFort.Id)
 	pokestopMutex.Lock()
 	defer pokestopMutex.Unlock()
@@ -979,7 +989,7 @@ func UpdatePokestopRecordWithGetMapFortsOutProto(ctx context.Context, db db.DbDe
 	}
 
 	pokestop.updatePokestopFromGetMapFortsOutProto(mapFort)
-	savePokestopRecord(ctx, db, pokestop)
+	savePokestopRecordAsAtTime(ctx, db, pokestop, timestampMs/1000)
 	return true, fmt.Sprintf("%s %s", mapFort.Id, mapFort.Name)
 }
 
@@ -987,7 +997,7 @@ func GetPokestopPositions(details db.DbDetails, geofence *geojson.Feature) ([]db
 	return db.GetPokestopPositions(details, geofence)
 }
 
-func UpdatePokestopWithContestData(ctx context.Context, db db.DbDetails, request *pogo.GetContestDataProto, contestData *pogo.GetContestDataOutProto) string {
+func UpdatePokestopWithContestData(ctx context.Context, db db.DbDetails, request *pogo.GetContestDataProto, contestData *pogo.GetContestDataOutProto, timestampMs int64) string {
 	if contestData.ContestIncident == nil || len(contestData.ContestIncident.Contests) == 0 {
 		return "No contests found"
 	}
@@ -1026,7 +1036,7 @@ func UpdatePokestopWithContestData(ctx context.Context, db db.DbDetails, request
 	}
 
 	pokestop.updatePokestopFromGetContestDataOutProto(contest)
-	savePokestopRecord(ctx, db, pokestop)
+	savePokestopRecordAsAtTime(ctx, db, pokestop, timestampMs/1000)
 
 	return fmt.Sprintf("Contest %s", fortId)
 }
@@ -1035,7 +1045,7 @@ func getFortIdFromContest(id string) string {
 	return strings.Split(id, "-")[0]
 }
 
-func UpdatePokestopWithPokemonSizeContestEntry(ctx context.Context, db db.DbDetails, request *pogo.GetPokemonSizeLeaderboardEntryProto, contestData *pogo.GetPokemonSizeLeaderboardEntryOutProto) string {
+func UpdatePokestopWithPokemonSizeContestEntry(ctx context.Context, db db.DbDetails, request *pogo.GetPokemonSizeLeaderboardEntryProto, contestData *pogo.GetPokemonSizeLeaderboardEntryOutProto, timestampMs int64) string {
 	fortId := getFortIdFromContest(request.GetContestId())
 
 	pokestopMutex, _ := pokestopStripedMutex.GetLock(fortId)
@@ -1054,7 +1064,7 @@ func UpdatePokestopWithPokemonSizeContestEntry(ctx context.Context, db db.DbDeta
 	}
 
 	pokestop.updatePokestopFromGetPokemonSizeContestEntryOutProto(contestData)
-	savePokestopRecord(ctx, db, pokestop)
+	savePokestopRecordAsAtTime(ctx, db, pokestop, timestampMs/1000)
 
 	return fmt.Sprintf("Contest Detail %s", fortId)
 }
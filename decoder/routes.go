@@ -7,6 +7,7 @@ import (
 	"golbat/db"
 	"golbat/pogo"
 	"golbat/util"
+	"golbat/webhooks"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
@@ -160,9 +161,20 @@ func saveRouteRecord(db db.DbDetails, route *Route) error {
 	}
 
 	routeCache.Set(route.Id, *route, ttlcache.DefaultTTL)
+	createRouteWebhooks(oldRoute, route)
 	return nil
 }
 
+// createRouteWebhooks sends a route webhook for a new route, or when the version of a
+// route changes after it has been edited
+func createRouteWebhooks(oldRoute *Route, route *Route) {
+	if oldRoute != nil && oldRoute.Version == route.Version {
+		return
+	}
+	areas := MatchStatsGeofence(route.StartLat, route.StartLon)
+	webhooksSender.AddMessage(webhooks.Route, buildRouteResult(route), areas)
+}
+
 func (route *Route) updateFromSharedRouteProto(sharedRouteProto *pogo.SharedRouteProto) {
 	route.Name = sharedRouteProto.GetName()
 	route.Description = sharedRouteProto.GetDescription()
@@ -3,6 +3,8 @@ package decoder
 import (
 	"golbat/config"
 	"golbat/geo"
+	"golbat/pogo"
+	"slices"
 	"strings"
 )
 
@@ -15,6 +17,40 @@ type ScanParameters struct {
 	ProcessGyms      bool
 	ProcessStations  bool
 	ProcessCells     bool
+
+	MinLevel       int // minimum trainer level for all methods other than social actions
+	SocialMinLevel int // minimum trainer level for social actions
+	AllowMethods   []string
+	DenyMethods    []string
+}
+
+// defaultMinLevel applies when a scan rule does not set min_level. Social actions are only
+// friend lookups, so are accepted from any level unless a rule sets social_min_level.
+const defaultMinLevel = 30
+
+// CheckMethod returns an empty string if the method may be processed from an account of the
+// given level, or otherwise the reason it is dropped. methodName is the method without its
+// METHOD_ prefix.
+func (p ScanParameters) CheckMethod(method int, methodName string, level int) string {
+	if slices.Contains(p.DenyMethods, methodName) {
+		return "method_denied"
+	}
+	if len(p.AllowMethods) > 0 && !slices.Contains(p.AllowMethods, methodName) {
+		return "method_not_allowed"
+	}
+	// GET_PLAYER is how an account's own state is tracked, which matters most for the low
+	// level accounts the gate refuses
+	if method == int(pogo.Method_METHOD_GET_PLAYER) {
+		return ""
+	}
+	minLevel := p.MinLevel
+	if method == int(pogo.InternalPlatformClientAction_INTERNAL_PROXY_SOCIAL_ACTION) {
+		minLevel = p.SocialMinLevel
+	}
+	if level < minLevel {
+		return "low_level"
+	}
+	return ""
 }
 
 func FindScanConfiguration(scanContext string, lat, lon float64) ScanParameters {
@@ -31,17 +67,8 @@ func FindScanConfiguration(scanContext string, lat, lon float64) ScanParameters
 				continue
 			}
 		}
-		if len(rule.ScanContext) > 0 {
-			found := false
-			for _, context := range rule.ScanContext {
-				if strings.EqualFold(context, scanContext) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
+		if len(rule.ScanContext) > 0 && !scanContextMatch(rule.ScanContext, scanContext) {
+			continue
 		}
 
 		// We have a match
@@ -52,6 +79,13 @@ func FindScanConfiguration(scanContext string, lat, lon float64) ScanParameters
 			}
 			return *value
 		}
+		minLevel, socialMinLevel := defaultMinLevel, 0
+		if rule.MinLevel != nil {
+			minLevel = *rule.MinLevel
+		}
+		if rule.SocialMinLevel != nil {
+			socialMinLevel = *rule.SocialMinLevel
+		}
 		return ScanParameters{
 			ProcessPokemon:   defaultTrue(rule.ProcessPokemon),
 			ProcessWild:      defaultTrue(rule.ProcessWilds),
@@ -61,6 +95,10 @@ func FindScanConfiguration(scanContext string, lat, lon float64) ScanParameters
 			ProcessPokestops: defaultTrue(rule.ProcessPokestops),
 			ProcessGyms:      defaultTrue(rule.ProcessGyms),
 			ProcessStations:  defaultTrue(rule.ProcessStations),
+			MinLevel:         minLevel,
+			SocialMinLevel:   socialMinLevel,
+			AllowMethods:     rule.AllowMethods,
+			DenyMethods:      rule.DenyMethods,
 		}
 	}
 
@@ -73,5 +111,27 @@ func FindScanConfiguration(scanContext string, lat, lon float64) ScanParameters
 		ProcessGyms:      true,
 		ProcessPokestops: true,
 		ProcessStations:  true,
+		MinLevel:         defaultMinLevel,
+	}
+}
+
+// RawCredentialInScope returns true if data with the given scan context and location is within
+// the contexts and areas permitted for a raw credential. Empty lists permit everything.
+func RawCredentialInScope(credential *config.RawCredential, scanContext string, lat, lon float64) bool {
+	if len(credential.ScanContext) > 0 && !scanContextMatch(credential.ScanContext, scanContext) {
+		return false
+	}
+	if len(credential.AreaNames) > 0 && !geo.AreaMatchWithWildcards(MatchStatsGeofence(lat, lon), credential.AreaNames) {
+		return false
+	}
+	return true
+}
+
+func scanContextMatch(contexts []string, scanContext string) bool {
+	for _, context := range contexts {
+		if strings.EqualFold(context, scanContext) {
+			return true
+		}
 	}
+	return false
 }
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"golbat/db"
 	"golbat/pogo"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/golang/geo/s2"
 	"github.com/jellydator/ttlcache/v3"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/guregu/null.v4"
@@ -22,6 +24,11 @@ type Spawnpoint struct {
 	Updated    int64    `db:"updated"`
 	LastSeen   int64    `db:"last_seen"`
 	DespawnSec null.Int `db:"despawn_sec"`
+	// SeenMinutes has a bit set for each minute of the hour a pokemon has been seen at
+	// the spawnpoint, and GoneMinutes for each minute its pokemon has been seen to have
+	// disappeared, see inferSpawnPattern
+	SeenMinutes uint64 `db:"seen_minutes"`
+	GoneMinutes uint64 `db:"gone_minutes"`
 }
 
 //CREATE TABLE `spawnpoint` (
@@ -31,6 +38,8 @@ type Spawnpoint struct {
 //`updated` int unsigned NOT NULL DEFAULT '0',
 //`last_seen` int unsigned NOT NULL DEFAULT '0',
 //`despawn_sec` smallint unsigned DEFAULT NULL,
+//`seen_minutes` bigint unsigned NOT NULL DEFAULT 0,
+//`gone_minutes` bigint unsigned NOT NULL DEFAULT 0,
 //PRIMARY KEY (`id`),
 //KEY `ix_coords` (`lat`,`lon`),
 //KEY `ix_updated` (`updated`),
@@ -45,7 +54,7 @@ func getSpawnpointRecord(ctx context.Context, db db.DbDetails, spawnpointId int6
 	}
 	spawnpoint := Spawnpoint{}
 
-	err := db.GeneralDb.GetContext(ctx, &spawnpoint, "SELECT id, lat, lon, updated, last_seen, despawn_sec FROM spawnpoint WHERE id = ?", spawnpointId)
+	err := db.GeneralDb.GetContext(ctx, &spawnpoint, "SELECT id, lat, lon, updated, last_seen, despawn_sec, seen_minutes, gone_minutes FROM spawnpoint WHERE id = ?", spawnpointId)
 
 	statsCollector.IncDbQuery("select spawnpoint", err)
 	if err == sql.ErrNoRows {
@@ -98,6 +107,12 @@ func spawnpointUpdateFromWild(ctx context.Context, db db.DbDetails, wildPokemon
 		panic(err)
 	}
 
+	spawnpointMutex, _ := spawnpointStripedMutex.GetLock(strconv.FormatInt(spawnId, 10))
+	spawnpointMutex.Lock()
+	defer spawnpointMutex.Unlock()
+
+	seenMinute := time.UnixMilli(timestampMs).Minute()
+
 	if wildPokemon.TimeTillHiddenMs <= 90000 && wildPokemon.TimeTillHiddenMs > 0 {
 		expireTimeStamp := (timestampMs + int64(wildPokemon.TimeTillHiddenMs)) / 1000
 
@@ -109,7 +124,7 @@ func spawnpointUpdateFromWild(ctx context.Context, db db.DbDetails, wildPokemon
 			Lon:        wildPokemon.Longitude,
 			DespawnSec: null.IntFrom(int64(secondOfHour)),
 		}
-		spawnpointUpdate(ctx, db, &spawnpoint)
+		spawnpointUpdate(ctx, db, &spawnpoint, seenMinute)
 	} else {
 		spawnPoint, _ := getSpawnpointRecord(ctx, db, spawnId)
 		if spawnPoint == nil {
@@ -118,33 +133,45 @@ func spawnpointUpdateFromWild(ctx context.Context, db db.DbDetails, wildPokemon
 				Lat: wildPokemon.Latitude,
 				Lon: wildPokemon.Longitude,
 			}
-			spawnpointUpdate(ctx, db, &spawnpoint)
+			spawnpointUpdate(ctx, db, &spawnpoint, seenMinute)
 		} else {
-			spawnpointSeen(ctx, db, spawnId)
+			spawnpointSeen(ctx, db, spawnId, seenMinute)
 		}
 	}
 }
 
-func spawnpointUpdate(ctx context.Context, db db.DbDetails, spawnpoint *Spawnpoint) {
+// spawnpointUpdate saves a changed spawnpoint. The spawnpoint mutex must be held.
+func spawnpointUpdate(ctx context.Context, db db.DbDetails, spawnpoint *Spawnpoint, seenMinute int) {
 	oldSpawnpoint, _ := getSpawnpointRecord(ctx, db, spawnpoint.Id)
 
 	if oldSpawnpoint != nil && !hasChangesSpawnpoint(oldSpawnpoint, spawnpoint) {
+		spawnpointSeen(ctx, db, spawnpoint.Id, seenMinute)
 		return
 	}
 
+	spawnpoint.SeenMinutes = 1 << seenMinute
+	if oldSpawnpoint != nil && !(oldSpawnpoint.DespawnSec.Valid && spawnpoint.DespawnSec.Valid) {
+		// sightings are only discarded when the despawn time moves, as the spawnpoint
+		// will then have a different pattern
+		spawnpoint.SeenMinutes |= oldSpawnpoint.SeenMinutes
+		spawnpoint.GoneMinutes = oldSpawnpoint.GoneMinutes
+	}
+
 	//log.Println(cmp.Diff(oldSpawnpoint, spawnpoint))
 
 	spawnpoint.Updated = time.Now().Unix()  // ensure future updates are set correctly
 	spawnpoint.LastSeen = time.Now().Unix() // ensure future updates are set correctly
 
-	_, err := db.GeneralDb.NamedExecContext(ctx, "INSERT INTO spawnpoint (id, lat, lon, updated, last_seen, despawn_sec)"+
-		"VALUES (:id, :lat, :lon, :updated, :last_seen, :despawn_sec)"+
+	_, err := db.GeneralDb.NamedExecContext(ctx, "INSERT INTO spawnpoint (id, lat, lon, updated, last_seen, despawn_sec, seen_minutes, gone_minutes)"+
+		"VALUES (:id, :lat, :lon, :updated, :last_seen, :despawn_sec, :seen_minutes, :gone_minutes)"+
 		"ON DUPLICATE KEY UPDATE "+
 		"lat=VALUES(lat),"+
 		"lon=VALUES(lon),"+
 		"updated=VALUES(updated),"+
 		"last_seen=VALUES(last_seen),"+
-		"despawn_sec=VALUES(despawn_sec)", spawnpoint)
+		"despawn_sec=VALUES(despawn_sec),"+
+		"seen_minutes=VALUES(seen_minutes),"+
+		"gone_minutes=VALUES(gone_minutes)", spawnpoint)
 
 	statsCollector.IncDbQuery("insert spawnpoint", err)
 	if err != nil {
@@ -155,7 +182,9 @@ func spawnpointUpdate(ctx context.Context, db db.DbDetails, spawnpoint *Spawnpoi
 	spawnpointCache.Set(spawnpoint.Id, *spawnpoint, ttlcache.DefaultTTL)
 }
 
-func spawnpointSeen(ctx context.Context, db db.DbDetails, spawnpointId int64) {
+// spawnpointSeen records a sighting of an unchanged spawnpoint. The spawnpoint mutex must be
+// held.
+func spawnpointSeen(ctx context.Context, db db.DbDetails, spawnpointId int64, seenMinute int) {
 	inMemorySpawnpoint := spawnpointCache.Get(spawnpointId)
 	if inMemorySpawnpoint == nil {
 		// This should never happen, since all routes here have previously created a spawnpoint in the cache
@@ -165,12 +194,15 @@ func spawnpointSeen(ctx context.Context, db db.DbDetails, spawnpointId int64) {
 	spawnpoint := inMemorySpawnpoint.Value()
 	now := time.Now().Unix()
 
-	if now-spawnpoint.LastSeen > 3600 {
+	seenMinutes := spawnpoint.SeenMinutes | 1<<seenMinute
+
+	if now-spawnpoint.LastSeen > 3600 || seenMinutes != spawnpoint.SeenMinutes {
 		spawnpoint.LastSeen = now
+		spawnpoint.SeenMinutes = seenMinutes
 
 		_, err := db.GeneralDb.ExecContext(ctx, "UPDATE spawnpoint "+
-			"SET last_seen=? "+
-			"WHERE id = ? ", now, spawnpointId)
+			"SET last_seen=?, seen_minutes=seen_minutes | ? "+
+			"WHERE id = ? ", now, seenMinutes, spawnpointId)
 		statsCollector.IncDbQuery("update spawnpoint", err)
 		if err != nil {
 			log.Printf("Error updating spawnpoint last seen %s", err)
@@ -179,3 +211,112 @@ func spawnpointSeen(ctx context.Context, db db.DbDetails, spawnpointId int64) {
 		spawnpointCache.Set(spawnpoint.Id, spawnpoint, ttlcache.DefaultTTL)
 	}
 }
+
+// spawnpointSighting is a spawnpoint shown with a wild pokemon in a GMO
+type spawnpointSighting struct {
+	id     int64
+	lat    float64
+	lon    float64
+	seenMs int64
+}
+
+// wildPokemonRange is the distance in metres from the scanning device within which every
+// wild pokemon is shown in a GMO, so a spawnpoint within it no longer shown has no pokemon
+const wildPokemonRange = 60
+
+const earthRadiusMetres = 6371010
+
+// spawnpointSightingCache holds the spawnpoints last shown with a wild pokemon in each GMO
+// cell, until they are too old for a disappearance to be recorded
+var spawnpointSightingCache *ttlcache.Cache[uint64, []spawnpointSighting]
+
+// UpdateSpawnpointDisappearances records the minute that the pokemon of each spawnpoint
+// shown in a recent GMO disappeared, when a GMO scanned from close enough to it no longer
+// shows it, and remembers the spawnpoints shown in this GMO.
+func UpdateSpawnpointDisappearances(ctx context.Context, db db.DbDetails, lat, lon float64, mapCells []uint64, wildPokemonList []RawWildPokemonData, timestampMs int64) {
+	if lat == 0 && lon == 0 {
+		return
+	}
+
+	shown := make(map[uint64][]spawnpointSighting)
+	for _, wild := range wildPokemonList {
+		spawnId, err := strconv.ParseInt(wild.Data.SpawnPointId, 16, 64)
+		if err != nil {
+			continue
+		}
+		shown[wild.Cell] = append(shown[wild.Cell], spawnpointSighting{
+			id:     spawnId,
+			lat:    wild.Data.Latitude,
+			lon:    wild.Data.Longitude,
+			seenMs: int64(wild.Timestamp),
+		})
+	}
+
+	scanLocation := s2.LatLngFromDegrees(lat, lon)
+	var goneIds []int64
+	for _, cellId := range mapCells {
+		sightings := shown[cellId]
+
+		cellMutex, _ := s2cellStripedMutex.GetLock(strconv.FormatUint(cellId, 10))
+		cellMutex.Lock()
+		if previous := spawnpointSightingCache.Get(cellId); previous != nil {
+			for _, sighting := range previous.Value() {
+				if slices.ContainsFunc(sightings, func(s spawnpointSighting) bool { return s.id == sighting.id }) {
+					continue
+				}
+				if timestampMs <= sighting.seenMs {
+					// this GMO is older than the sighting
+					sightings = append(sightings, sighting)
+					continue
+				}
+				if timestampMs-sighting.seenMs > spawnGoneMaxMinutes*60*1000 {
+					continue
+				}
+				distance := scanLocation.Distance(s2.LatLngFromDegrees(sighting.lat, sighting.lon)).Radians() * earthRadiusMetres
+				if distance <= wildPokemonRange {
+					goneIds = append(goneIds, sighting.id)
+				} else {
+					sightings = append(sightings, sighting)
+				}
+			}
+		}
+		if len(sightings) > 0 {
+			spawnpointSightingCache.Set(cellId, sightings, ttlcache.DefaultTTL)
+		} else {
+			spawnpointSightingCache.Delete(cellId)
+		}
+		cellMutex.Unlock()
+	}
+
+	goneMinute := time.UnixMilli(timestampMs).Minute()
+	for _, spawnId := range goneIds {
+		spawnpointGone(ctx, db, spawnId, goneMinute)
+	}
+}
+
+func spawnpointGone(ctx context.Context, db db.DbDetails, spawnpointId int64, goneMinute int) {
+	spawnpointMutex, _ := spawnpointStripedMutex.GetLock(strconv.FormatInt(spawnpointId, 10))
+	spawnpointMutex.Lock()
+	defer spawnpointMutex.Unlock()
+
+	spawnpoint, err := getSpawnpointRecord(ctx, db, spawnpointId)
+	if err != nil || spawnpoint == nil {
+		return
+	}
+
+	goneMinutes := spawnpoint.GoneMinutes | 1<<goneMinute
+	if goneMinutes == spawnpoint.GoneMinutes {
+		return
+	}
+	spawnpoint.GoneMinutes = goneMinutes
+
+	_, err = db.GeneralDb.ExecContext(ctx, "UPDATE spawnpoint "+
+		"SET gone_minutes=gone_minutes | ? "+
+		"WHERE id = ? ", goneMinutes, spawnpointId)
+	statsCollector.IncDbQuery("update spawnpoint", err)
+	if err != nil {
+		log.Errorf("Error updating spawnpoint gone minutes %s", err)
+		return
+	}
+	spawnpointCache.Set(spawnpoint.Id, *spawnpoint, ttlcache.DefaultTTL)
+}
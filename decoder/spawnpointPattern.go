@@ -0,0 +1,196 @@
+package decoder
+
+import (
+	"math"
+	"math/bits"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	SpawnTypeUnknown = "unknown"
+	SpawnType30      = "30m"    // a single 30 minute window each hour
+	SpawnType60      = "60m"    // a single 60 minute window each hour
+	SpawnTypeDouble  = "double" // two windows each hour, with the pokemon hidden in between
+)
+
+// spawnDoubleGapMinutes is the shortest gap in sightings before a spawnpoint with a
+// 60 minute window is considered to be hidden for part of the hour
+const spawnDoubleGapMinutes = 15
+
+// spawnGoneMaxMinutes is the longest time after a sighting that a pokemon no longer being
+// shown is recorded as having disappeared
+const spawnGoneMaxMinutes = 5
+
+// SpawnPattern is the spawn window inferred for a spawnpoint. Windows are given in seconds
+// of the hour, with the end exclusive, and may wrap around the hour. A window covering the
+// whole hour starts and ends at the same second. A double spawn has a second window, ending
+// at the despawn second, after the pokemon has been hidden for part of the hour.
+type SpawnPattern struct {
+	SpawnType         string   `json:"spawn_type"`
+	WindowStart       null.Int `json:"window_start"`
+	WindowEnd         null.Int `json:"window_end"`
+	SecondWindowStart null.Int `json:"second_window_start"`
+	SecondWindowEnd   null.Int `json:"second_window_end"`
+	Confidence        float64  `json:"confidence"`
+}
+
+// inferSpawnPattern classifies a spawnpoint from the minutes of the hour it has been seen
+// in, the minutes its pokemon has been seen to disappear in, and its despawn second, if a
+// TTH has been seen.
+//
+// Without a despawn second the type is unknown, and the window is the shortest span of the
+// hour covering every sighting. With one, sightings are placed in the hour before despawn:
+// if all fall in the last 30 minutes the spawnpoint is taken to be a 30 minute spawn. As a
+// 60 minute spawn would be seen in its first half as often as its second, confidence is
+// the chance that the sightings would not all have fallen in the last 30 minutes otherwise.
+// Sightings more than 30 minutes before despawn make it a 60 minute spawn, unless the
+// pokemon has been seen to disappear too long before the despawn, or a gap in the sightings
+// is long enough for part of the hour to be hidden, and unlikely enough to be chance.
+func inferSpawnPattern(seenMinutes uint64, goneMinutes uint64, despawnSec null.Int) SpawnPattern {
+	seen := bits.OnesCount64(seenMinutes)
+	if seen == 0 {
+		return SpawnPattern{SpawnType: SpawnTypeUnknown}
+	}
+
+	if !despawnSec.Valid {
+		pattern := SpawnPattern{SpawnType: SpawnTypeUnknown}
+		if seen < 60 {
+			gapStart, gapLength := largestSightingGap(seenMinutes)
+			start := (gapStart + gapLength) % 60
+			end := gapStart
+			pattern.WindowStart = null.IntFrom(int64(start * 60))
+			pattern.WindowEnd = null.IntFrom(int64(end * 60))
+		}
+		return pattern
+	}
+
+	despawn := despawnSec.Int64
+	despawnMinute := int(despawn / 60)
+	before := minutesBeforeDespawn(seenMinutes, despawnMinute)
+	earliest := 63 - bits.LeadingZeros64(before)
+
+	if earliest < 30 {
+		return SpawnPattern{
+			SpawnType:   SpawnType30,
+			WindowStart: null.IntFrom((despawn + 1800) % 3600),
+			WindowEnd:   null.IntFrom(despawn),
+			Confidence:  1 - math.Pow(0.5, float64(seen)),
+		}
+	}
+
+	// the longest run of minutes without a sighting between the first and last sightings
+	// of the hour, as the number of minutes before despawn of its first minute
+	gapFirst, gapLength := 0, 0
+	for n := bits.TrailingZeros64(before) + 1; n < earliest; {
+		if before&(1<<n) != 0 {
+			n++
+			continue
+		}
+		length := 0
+		for before&(1<<(n+length)) == 0 {
+			length++
+		}
+		if length > gapLength {
+			gapFirst, gapLength = n+length-1, length
+		}
+		n += length
+	}
+
+	// a disappearance recorded from two minutes before the despawn until too long before it
+	// to have followed the despawn shows the pokemon is hidden for part of the hour
+	var hiddenMask uint64 = (1<<(60-spawnGoneMaxMinutes) - 1) &^ 0b11
+	seenHidden := minutesBeforeDespawn(goneMinutes, despawnMinute)&hiddenMask != 0
+
+	if gapLength == 0 || !seenHidden && gapLength < spawnDoubleGapMinutes {
+		// no part of the hour is left where the pokemon could be hidden
+		return SpawnPattern{
+			SpawnType:   SpawnType60,
+			WindowStart: null.IntFrom(despawn),
+			WindowEnd:   null.IntFrom(despawn),
+			Confidence:  1,
+		}
+	}
+
+	// the chance that a 60 minute spawn would have been seen within a gap this long
+	doubleConfidence := 1.0
+	if !seenHidden {
+		doubleConfidence = 1 - math.Pow(1-float64(gapLength)/60, float64(seen))
+	}
+	if doubleConfidence < 0.5 {
+		return SpawnPattern{
+			SpawnType:   SpawnType60,
+			WindowStart: null.IntFrom(despawn),
+			WindowEnd:   null.IntFrom(despawn),
+			Confidence:  1 - doubleConfidence,
+		}
+	}
+
+	secondOfHour := func(minutesBefore int) null.Int {
+		return null.IntFrom(int64((despawnMinute-minutesBefore+60)%60) * 60)
+	}
+	return SpawnPattern{
+		SpawnType:         SpawnTypeDouble,
+		WindowStart:       secondOfHour(earliest),
+		WindowEnd:         secondOfHour(gapFirst),
+		SecondWindowStart: secondOfHour(gapFirst - gapLength),
+		SecondWindowEnd:   null.IntFrom(despawn),
+		Confidence:        doubleConfidence,
+	}
+}
+
+// minutesBeforeDespawn rotates minutes of the hour so that bit n is n minutes before the
+// despawn minute
+func minutesBeforeDespawn(minutes uint64, despawnMinute int) uint64 {
+	var before uint64
+	for minute := 0; minute < 60; minute++ {
+		if minutes&(1<<minute) != 0 {
+			before |= 1 << ((despawnMinute - minute + 60) % 60)
+		}
+	}
+	return before
+}
+
+// largestSightingGap returns the first minute and length of the longest run of minutes,
+// wrapping around the hour, in which the spawnpoint has not been seen. At least one
+// minute must have been seen.
+func largestSightingGap(seenMinutes uint64) (int, int) {
+	bestStart, bestLength := 0, 0
+	for start := 0; start < 60; start++ {
+		if seenMinutes&(1<<start) != 0 || seenMinutes&(1<<((start+59)%60)) == 0 {
+			// only measure from the first minute of each gap
+			continue
+		}
+		length := 0
+		for seenMinutes&(1<<((start+length)%60)) == 0 {
+			length++
+		}
+		if length > bestLength {
+			bestStart, bestLength = start, length
+		}
+	}
+	return bestStart, bestLength
+}
+
+// estimateUnverifiedDespawn returns a lower bound for when a pokemon seen at the given
+// second of the hour will despawn, from the windows the spawnpoint has been seen in. The
+// bound is given as seconds from the sighting, or 0 if the windows give no estimate.
+func estimateUnverifiedDespawn(pattern SpawnPattern, secondOfHour int64) int64 {
+	if remaining := remainingInWindow(pattern.WindowStart, pattern.WindowEnd, secondOfHour); remaining > 0 {
+		return remaining
+	}
+	return remainingInWindow(pattern.SecondWindowStart, pattern.SecondWindowEnd, secondOfHour)
+}
+
+func remainingInWindow(windowStart null.Int, windowEnd null.Int, secondOfHour int64) int64 {
+	if !windowStart.Valid || !windowEnd.Valid {
+		return 0
+	}
+	start, end := windowStart.Int64, windowEnd.Int64
+	intoWindow := (secondOfHour - start + 3600) % 3600
+	windowLength := (end - start + 3600) % 3600
+	if intoWindow >= windowLength {
+		return 0
+	}
+	return windowLength - intoWindow
+}
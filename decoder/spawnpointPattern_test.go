@@ -0,0 +1,185 @@
+package decoder
+
+import (
+	"math"
+	"testing"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// minutesOf returns the bitmap of the given minutes of the hour
+func minutesOf(minutes ...int) uint64 {
+	var bitmap uint64
+	for _, minute := range minutes {
+		bitmap |= 1 << minute
+	}
+	return bitmap
+}
+
+// minuteRange returns the bitmap of the minutes from first to last, wrapping around the hour
+func minuteRange(first, last int) uint64 {
+	var bitmap uint64
+	for minute := first; ; minute = (minute + 1) % 60 {
+		bitmap |= 1 << minute
+		if minute == last {
+			return bitmap
+		}
+	}
+}
+
+func TestLargestSightingGap(t *testing.T) {
+	tests := []struct {
+		name        string
+		seenMinutes uint64
+		wantStart   int
+		wantLength  int
+	}{
+		{"single run", minuteRange(10, 39), 40, 30},
+		{"gap wraps the hour", minuteRange(20, 40), 41, 39},
+		{"sightings wrap the hour", minuteRange(50, 9), 10, 40},
+		{"longest of three gaps", minutesOf(0, 10, 40), 11, 29},
+		{"single sighting", minutesOf(30), 31, 59},
+	}
+
+	for _, test := range tests {
+		start, length := largestSightingGap(test.seenMinutes)
+		if start != test.wantStart || length != test.wantLength {
+			t.Errorf("%s: largestSightingGap = (%d, %d), want (%d, %d)", test.name, start, length, test.wantStart, test.wantLength)
+		}
+	}
+}
+
+func TestInferSpawnPattern(t *testing.T) {
+	tests := []struct {
+		name        string
+		seenMinutes uint64
+		goneMinutes uint64
+		despawnSec  null.Int
+		want        SpawnPattern
+	}{
+		{
+			name: "never seen",
+			want: SpawnPattern{SpawnType: SpawnTypeUnknown},
+		},
+		{
+			name:        "unknown despawn wrapping the hour",
+			seenMinutes: minuteRange(50, 5),
+			want: SpawnPattern{
+				SpawnType:   SpawnTypeUnknown,
+				WindowStart: null.IntFrom(50 * 60),
+				WindowEnd:   null.IntFrom(6 * 60),
+			},
+		},
+		{
+			name:        "30 minute spawn wrapping the hour",
+			seenMinutes: minutesOf(55, 0, 10, 19),
+			despawnSec:  null.IntFrom(1200),
+			want: SpawnPattern{
+				SpawnType:   SpawnType30,
+				WindowStart: null.IntFrom(3000),
+				WindowEnd:   null.IntFrom(1200),
+				Confidence:  1 - math.Pow(0.5, 4),
+			},
+		},
+		{
+			name:        "60 minute spawn",
+			seenMinutes: minutesOf(35, 45, 55, 5, 15, 25),
+			despawnSec:  null.IntFrom(1800),
+			want: SpawnPattern{
+				SpawnType:   SpawnType60,
+				WindowStart: null.IntFrom(1800),
+				WindowEnd:   null.IntFrom(1800),
+				Confidence:  1,
+			},
+		},
+		{
+			name:        "60 minute spawn gone after despawn",
+			seenMinutes: minutesOf(35, 45, 55, 5, 15, 25),
+			goneMinutes: minutesOf(32),
+			despawnSec:  null.IntFrom(1800),
+			want: SpawnPattern{
+				SpawnType:   SpawnType60,
+				WindowStart: null.IntFrom(1800),
+				WindowEnd:   null.IntFrom(1800),
+				Confidence:  1,
+			},
+		},
+		{
+			name:        "60 minute spawn with a gap likely to be chance",
+			seenMinutes: minutesOf(31, 47),
+			despawnSec:  null.IntFrom(1800),
+			want: SpawnPattern{
+				SpawnType:   SpawnType60,
+				WindowStart: null.IntFrom(1800),
+				WindowEnd:   null.IntFrom(1800),
+				Confidence:  math.Pow(1-15.0/60, 2),
+			},
+		},
+		{
+			name:        "double spawn from a gap wrapping the hour",
+			seenMinutes: minuteRange(15, 18) | minuteRange(55, 9),
+			despawnSec:  null.IntFrom(600),
+			want: SpawnPattern{
+				SpawnType:         SpawnTypeDouble,
+				WindowStart:       null.IntFrom(15 * 60),
+				WindowEnd:         null.IntFrom(19 * 60),
+				SecondWindowStart: null.IntFrom(55 * 60),
+				SecondWindowEnd:   null.IntFrom(600),
+				Confidence:        1 - math.Pow(1-36.0/60, 19),
+			},
+		},
+		{
+			name:        "double spawn seen to disappear",
+			seenMinutes: minuteRange(31, 40) | minuteRange(46, 29),
+			goneMinutes: minutesOf(42),
+			despawnSec:  null.IntFrom(1800),
+			want: SpawnPattern{
+				SpawnType:         SpawnTypeDouble,
+				WindowStart:       null.IntFrom(31 * 60),
+				WindowEnd:         null.IntFrom(41 * 60),
+				SecondWindowStart: null.IntFrom(46 * 60),
+				SecondWindowEnd:   null.IntFrom(1800),
+				Confidence:        1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got := inferSpawnPattern(test.seenMinutes, test.goneMinutes, test.despawnSec)
+		if math.Abs(got.Confidence-test.want.Confidence) > 1e-9 {
+			t.Errorf("%s: confidence = %f, want %f", test.name, got.Confidence, test.want.Confidence)
+		}
+		got.Confidence = test.want.Confidence
+		if got != test.want {
+			t.Errorf("%s: inferSpawnPattern = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestEstimateUnverifiedDespawn(t *testing.T) {
+	double := SpawnPattern{
+		SpawnType:         SpawnTypeDouble,
+		WindowStart:       null.IntFrom(15 * 60),
+		WindowEnd:         null.IntFrom(19 * 60),
+		SecondWindowStart: null.IntFrom(55 * 60),
+		SecondWindowEnd:   null.IntFrom(600),
+	}
+
+	tests := []struct {
+		name         string
+		pattern      SpawnPattern
+		secondOfHour int64
+		want         int64
+	}{
+		{"first window", double, 16 * 60, 3 * 60},
+		{"second window across the hour", double, 58 * 60, 12 * 60},
+		{"between windows", double, 30 * 60, 0},
+		{"unknown pattern", SpawnPattern{SpawnType: SpawnTypeUnknown}, 0, 0},
+	}
+
+	for _, test := range tests {
+		if got := estimateUnverifiedDespawn(test.pattern, test.secondOfHour); got != test.want {
+			t.Errorf("%s: estimateUnverifiedDespawn = %d, want %d", test.name, got, test.want)
+		}
+	}
+}
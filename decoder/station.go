@@ -9,6 +9,7 @@ import (
 	"golbat/db"
 	"golbat/pogo"
 	"golbat/util"
+	"golbat/webhooks"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
@@ -17,34 +18,37 @@ import (
 )
 
 type Station struct {
-	Id                string  `db:"id"`
-	Lat               float64 `db:"lat"`
-	Lon               float64 `db:"lon"`
-	Name              string  `db:"name"`
-	CellId            int64   `db:"cell_id"`
-	StartTime         int64   `db:"start_time"`
-	EndTime           int64   `db:"end_time"`
-	CooldownComplete  int64   `db:"cooldown_complete"`
-	IsBattleAvailable bool    `db:"is_battle_available"`
-	IsInactive        bool    `db:"is_inactive"`
-	Updated           int64   `db:"updated"`
-
-	BattleLevel            null.Int `db:"battle_level"`
-	BattleStart            null.Int `db:"battle_start"`
-	BattleEnd              null.Int `db:"battle_end"`
-	BattlePokemonId        null.Int `db:"battle_pokemon_id"`
-	BattlePokemonForm      null.Int `db:"battle_pokemon_form"`
-	BattlePokemonCostume   null.Int `db:"battle_pokemon_costume"`
-	BattlePokemonGender    null.Int `db:"battle_pokemon_gender"`
-	BattlePokemonAlignment null.Int `db:"battle_pokemon_alignment"`
-	BattlePokemonBreadMode null.Int `db:"battle_pokemon_bread_mode"`
-	BattlePokemonMove1     null.Int `db:"battle_pokemon_move_1"`
-	BattlePokemonMove2     null.Int `db:"battle_pokemon_move_2"`
-
-	TotalStationedPokemon null.Int    `db:"total_stationed_pokemon"`
-	StationedPokemon      null.String `db:"stationed_pokemon"`
+	Id                string  `db:"id" json:"id"`
+	Lat               float64 `db:"lat" json:"lat"`
+	Lon               float64 `db:"lon" json:"lon"`
+	Name              string  `db:"name" json:"name"`
+	CellId            int64   `db:"cell_id" json:"cell_id"`
+	StartTime         int64   `db:"start_time" json:"start_time"`
+	EndTime           int64   `db:"end_time" json:"end_time"`
+	CooldownComplete  int64   `db:"cooldown_complete" json:"cooldown_complete"`
+	IsBattleAvailable bool    `db:"is_battle_available" json:"is_battle_available"`
+	IsInactive        bool    `db:"is_inactive" json:"is_inactive"`
+	Updated           int64   `db:"updated" json:"updated"`
+
+	BattleLevel            null.Int `db:"battle_level" json:"battle_level"`
+	BattleStart            null.Int `db:"battle_start" json:"battle_start"`
+	BattleEnd              null.Int `db:"battle_end" json:"battle_end"`
+	BattlePokemonId        null.Int `db:"battle_pokemon_id" json:"battle_pokemon_id"`
+	BattlePokemonForm      null.Int `db:"battle_pokemon_form" json:"battle_pokemon_form"`
+	BattlePokemonCostume   null.Int `db:"battle_pokemon_costume" json:"battle_pokemon_costume"`
+	BattlePokemonGender    null.Int `db:"battle_pokemon_gender" json:"battle_pokemon_gender"`
+	BattlePokemonAlignment null.Int `db:"battle_pokemon_alignment" json:"battle_pokemon_alignment"`
+	BattlePokemonBreadMode null.Int `db:"battle_pokemon_bread_mode" json:"battle_pokemon_bread_mode"`
+	BattlePokemonMove1     null.Int `db:"battle_pokemon_move_1" json:"battle_pokemon_move_1"`
+	BattlePokemonMove2     null.Int `db:"battle_pokemon_move_2" json:"battle_pokemon_move_2"`
+
+	TotalStationedPokemon null.Int    `db:"total_stationed_pokemon" json:"total_stationed_pokemon"`
+	StationedPokemon      null.String `db:"stationed_pokemon" json:"stationed_pokemon"`
 }
 
+// stationColumns are the columns of a Station
+const stationColumns = "id, lat, lon, name, cell_id, start_time, end_time, cooldown_complete, is_battle_available, is_inactive, updated, battle_level, battle_start, battle_end, battle_pokemon_id, battle_pokemon_form, battle_pokemon_costume, battle_pokemon_gender, battle_pokemon_alignment, battle_pokemon_bread_mode, battle_pokemon_move_1, battle_pokemon_move_2, total_stationed_pokemon, stationed_pokemon"
+
 func getStationRecord(ctx context.Context, db db.DbDetails, stationId string) (*Station, error) {
 	inMemoryStation := stationCache.Get(stationId)
 	if inMemoryStation != nil {
@@ -52,11 +56,7 @@ func getStationRecord(ctx context.Context, db db.DbDetails, stationId string) (*
 		return &station, nil
 	}
 	station := Station{}
-	err := db.GeneralDb.GetContext(ctx, &station,
-		`
-			SELECT id, lat, lon, name, cell_id, start_time, end_time, cooldown_complete, is_battle_available, is_inactive, updated, battle_level, battle_start, battle_end, battle_pokemon_id, battle_pokemon_form, battle_pokemon_costume, battle_pokemon_gender, battle_pokemon_alignment, battle_pokemon_bread_mode, battle_pokemon_move_1, battle_pokemon_move_2, total_stationed_pokemon, stationed_pokemon
-			FROM station WHERE id = ?
-		`, stationId)
+	err := db.GeneralDb.GetContext(ctx, &station, "SELECT "+stationColumns+" FROM station WHERE id = ?", stationId)
 	statsCollector.IncDbQuery("select station", err)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -69,9 +69,15 @@ func getStationRecord(ctx context.Context, db db.DbDetails, stationId string) (*
 	return &station, nil
 }
 
-func saveStationRecord(ctx context.Context, db db.DbDetails, station *Station) {
+// saveStationRecordAsAtTime saves a station as seen at the given time, refusing the update if
+// the stored station was seen more recently
+func saveStationRecordAsAtTime(ctx context.Context, db db.DbDetails, station *Station, now int64) {
 	oldStation, _ := getStationRecord(ctx, db, station.Id)
-	now := time.Now().Unix()
+	if oldStation != nil && oldStation.Updated > now {
+		statsCollector.IncStaleUpdates("station")
+		log.Debugf("Station %s: ignored update as at %d, older than stored %d", station.Id, now, oldStation.Updated)
+		return
+	}
 	if oldStation != nil && !hasChangesStation(oldStation, station) {
 		if oldStation.Updated > now-900 {
 			// if a gym is unchanged, but we did see it again after 15 minutes, then save again
@@ -147,6 +153,7 @@ func hasChangesStation(old *Station, new *Station) bool {
 		old.StationedPokemon != new.StationedPokemon ||
 		old.CooldownComplete != new.CooldownComplete ||
 		old.IsBattleAvailable != new.IsBattleAvailable ||
+		old.IsInactive != new.IsInactive ||
 		old.BattleLevel != new.BattleLevel ||
 		old.BattleStart != new.BattleStart ||
 		old.BattleEnd != new.BattleEnd ||
@@ -180,6 +187,8 @@ func (station *Station) updateFromStationProto(stationProto *pogo.StationProto,
 	station.EndTime = stationProto.EndTimeMs / 1000
 	station.CooldownComplete = stationProto.CooldownCompleteMs
 	station.IsBattleAvailable = stationProto.IsBreadBattleAvailable
+	// a station seen in a GMO is active again, see ClearRemovedForts
+	station.IsInactive = false
 	if battleDetails := stationProto.BattleDetails; battleDetails != nil {
 		station.BattleLevel = null.IntFrom(int64(battleDetails.BattleLevel))
 		station.BattleStart = null.IntFrom(battleDetails.BattleWindowStartMs / 1000)
@@ -235,7 +244,7 @@ func (station *Station) resetStationedPokemonFromStationDetailsNotFound() *Stati
 	return station
 }
 
-func ResetStationedPokemonWithStationDetailsNotFound(ctx context.Context, db db.DbDetails, request *pogo.GetStationedPokemonDetailsProto) string {
+func ResetStationedPokemonWithStationDetailsNotFound(ctx context.Context, db db.DbDetails, request *pogo.GetStationedPokemonDetailsProto, timestampMs int64) string {
 	stationId := request.StationId
 	stationMutex, _ := stationStripedMutex.GetLock(stationId)
 	stationMutex.Lock()
@@ -253,11 +262,11 @@ func ResetStationedPokemonWithStationDetailsNotFound(ctx context.Context, db db.
 	}
 
 	station.resetStationedPokemonFromStationDetailsNotFound()
-	saveStationRecord(ctx, db, station)
+	saveStationRecordAsAtTime(ctx, db, station, timestampMs/1000)
 	return fmt.Sprintf("StationedPokemonDetails %s", stationId)
 }
 
-func UpdateStationWithStationDetails(ctx context.Context, db db.DbDetails, request *pogo.GetStationedPokemonDetailsProto, stationDetails *pogo.GetStationedPokemonDetailsOutProto) string {
+func UpdateStationWithStationDetails(ctx context.Context, db db.DbDetails, request *pogo.GetStationedPokemonDetailsProto, stationDetails *pogo.GetStationedPokemonDetailsOutProto, timestampMs int64) string {
 	stationId := request.StationId
 	stationMutex, _ := stationStripedMutex.GetLock(stationId)
 	stationMutex.Lock()
@@ -275,10 +284,72 @@ func UpdateStationWithStationDetails(ctx context.Context, db db.DbDetails, reque
 	}
 
 	station.updateFromGetStationedPokemonDetailsOutProto(stationDetails)
-	saveStationRecord(ctx, db, station)
+	saveStationRecordAsAtTime(ctx, db, station, timestampMs/1000)
 	return fmt.Sprintf("StationedPokemonDetails %s", stationId)
 }
 
 func createStationWebhooks(oldStation *Station, station *Station) {
-	//TODO we need to define webhooks, are they needed for stations, or only for battles?
+	areas := MatchStatsGeofence(station.Lat, station.Lon)
+	if oldStation == nil || oldStation.IsInactive != station.IsInactive || oldStation.EndTime != station.EndTime {
+		webhooksSender.AddMessage(webhooks.Station, stationWebhook(station), areas)
+	}
+
+	if station.BattleLevel.ValueOrZero() > 0 && station.BattleEnd.ValueOrZero() > time.Now().Unix() &&
+		(oldStation == nil || oldStation.BattleLevel != station.BattleLevel ||
+			oldStation.BattlePokemonId != station.BattlePokemonId ||
+			oldStation.BattlePokemonForm != station.BattlePokemonForm ||
+			oldStation.BattleStart != station.BattleStart ||
+			oldStation.BattleEnd != station.BattleEnd) {
+		battleHook := map[string]interface{}{
+			"id":                        station.Id,
+			"name":                      station.Name,
+			"latitude":                  station.Lat,
+			"longitude":                 station.Lon,
+			"start_time":                station.StartTime,
+			"end_time":                  station.EndTime,
+			"is_battle_available":       station.IsBattleAvailable,
+			"battle_level":              station.BattleLevel.ValueOrZero(),
+			"battle_start":              station.BattleStart.ValueOrZero(),
+			"battle_end":                station.BattleEnd.ValueOrZero(),
+			"battle_pokemon_id":         station.BattlePokemonId.ValueOrZero(),
+			"battle_pokemon_form":       station.BattlePokemonForm.ValueOrZero(),
+			"battle_pokemon_costume":    station.BattlePokemonCostume.ValueOrZero(),
+			"battle_pokemon_gender":     station.BattlePokemonGender.ValueOrZero(),
+			"battle_pokemon_alignment":  station.BattlePokemonAlignment.ValueOrZero(),
+			"battle_pokemon_bread_mode": station.BattlePokemonBreadMode.ValueOrZero(),
+			"battle_pokemon_move_1":     station.BattlePokemonMove1.ValueOrZero(),
+			"battle_pokemon_move_2":     station.BattlePokemonMove2.ValueOrZero(),
+			"total_stationed_pokemon":   station.TotalStationedPokemon.ValueOrZero(),
+			"updated":                   station.Updated,
+		}
+		webhooksSender.AddMessage(webhooks.MaxBattle, battleHook, areas)
+	}
+}
+
+func stationWebhook(station *Station) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  station.Id,
+		"name":                station.Name,
+		"latitude":            station.Lat,
+		"longitude":           station.Lon,
+		"start_time":          station.StartTime,
+		"end_time":            station.EndTime,
+		"cooldown_complete":   station.CooldownComplete,
+		"is_battle_available": station.IsBattleAvailable,
+		"is_inactive":         station.IsInactive,
+		"updated":             station.Updated,
+	}
+}
+
+// createInactiveStationWebhooks sends a station webhook for each station which has just been
+// marked inactive. The stations are reloaded, as they have been removed from the cache.
+func createInactiveStationWebhooks(ctx context.Context, dbDetails db.DbDetails, ids []string) {
+	for _, id := range ids {
+		station, err := getStationRecord(ctx, dbDetails, id)
+		if err != nil || station == nil {
+			continue
+		}
+		areas := MatchStatsGeofence(station.Lat, station.Lon)
+		webhooksSender.AddMessage(webhooks.Station, stationWebhook(station), areas)
+	}
 }
@@ -120,22 +120,30 @@ func hasChangesWeather(old *Weather, new *Weather) bool {
 		!floatAlmostEqual(old.Longitude, new.Longitude, floatTolerance)
 }
 
+// weatherCellPolygon returns the corners of a weather cell as lat, lon pairs
+func weatherCellPolygon(cellId int64) [4][2]float64 {
+	s2cell := s2.CellFromCellID(s2.CellID(cellId))
+	var polygon [4][2]float64
+	for i := range []int{0, 1, 2, 3} {
+		vertex := s2cell.Vertex(i)
+		latLng := s2.LatLngFromPoint(vertex)
+		polygon[i] = [...]float64{latLng.Lat.Degrees(), latLng.Lng.Degrees()}
+	}
+	return polygon
+}
+
+func hasConditionChangeWeather(old *Weather, new *Weather) bool {
+	return old == nil || old.GameplayCondition.ValueOrZero() != new.GameplayCondition.ValueOrZero() ||
+		old.WarnWeather.ValueOrZero() != new.WarnWeather.ValueOrZero()
+}
+
 func createWeatherWebhooks(oldWeather *Weather, weather *Weather) {
-	if oldWeather == nil || oldWeather.GameplayCondition.ValueOrZero() != weather.GameplayCondition.ValueOrZero() ||
-		oldWeather.WarnWeather.ValueOrZero() != weather.WarnWeather.ValueOrZero() {
-
-		s2cell := s2.CellFromCellID(s2.CellID(weather.Id))
-		var polygon [4][2]float64
-		for i := range []int{0, 1, 2, 3} {
-			vertex := s2cell.Vertex(i)
-			latLng := s2.LatLngFromPoint(vertex)
-			polygon[i] = [...]float64{latLng.Lat.Degrees(), latLng.Lng.Degrees()}
-		}
+	if hasConditionChangeWeather(oldWeather, weather) {
 		weatherHook := map[string]interface{}{
 			"s2_cell_id":           weather.Id,
 			"latitude":             weather.Latitude,
 			"longitude":            weather.Longitude,
-			"polygon":              polygon,
+			"polygon":              weatherCellPolygon(weather.Id),
 			"gameplay_condition":   weather.GameplayCondition.ValueOrZero(),
 			"wind_direction":       weather.WindDirection.ValueOrZero(),
 			"cloud_level":          weather.CloudLevel.ValueOrZero(),
@@ -153,11 +161,19 @@ func createWeatherWebhooks(oldWeather *Weather, weather *Weather) {
 	}
 }
 
-func saveWeatherRecord(ctx context.Context, db db.DbDetails, weather *Weather) {
+// saveWeatherRecordAsAtTime saves weather as seen at the given time, refusing the update if
+// the stored weather was seen more recently
+func saveWeatherRecordAsAtTime(ctx context.Context, db db.DbDetails, weather *Weather, now int64) {
 	oldWeather, _ := getWeatherRecord(ctx, db, weather.Id)
+	if oldWeather != nil && oldWeather.Updated > now {
+		statsCollector.IncStaleUpdates("weather")
+		log.Debugf("Weather %d: ignored update as at %d, older than stored %d", weather.Id, now, oldWeather.Updated)
+		return
+	}
 	if oldWeather != nil && !hasChangesWeather(oldWeather, weather) {
 		return
 	}
+	weather.Updated = now
 
 	if oldWeather == nil {
 		res, err := db.GeneralDb.NamedExecContext(ctx,
@@ -167,7 +183,7 @@ func saveWeatherRecord(ctx context.Context, db db.DbDetails, weather *Weather) {
 				"VALUES ("+
 				":id, :latitude, :longitude, :level, :gameplay_condition, :wind_direction, :cloud_level, :rain_level, "+
 				":wind_level, :snow_level, :fog_level, :special_effect_level, :severity, :warn_weather, "+
-				"UNIX_TIMESTAMP())",
+				":updated)",
 			weather)
 		statsCollector.IncDbQuery("insert weather", err)
 		if err != nil {
@@ -190,7 +206,7 @@ func saveWeatherRecord(ctx context.Context, db db.DbDetails, weather *Weather) {
 			"special_effect_level = :special_effect_level, "+
 			"severity = :severity, "+
 			"warn_weather = :warn_weather, "+
-			"updated = UNIX_TIMESTAMP() "+
+			"updated = :updated "+
 			"WHERE id = :id",
 			weather)
 		statsCollector.IncDbQuery("update weather", err)
@@ -201,5 +217,21 @@ func saveWeatherRecord(ctx context.Context, db db.DbDetails, weather *Weather) {
 		_ = res
 	}
 	weatherCache.Set(weather.Id, *weather, ttlcache.DefaultTTL)
+	if hasConditionChangeWeather(oldWeather, weather) {
+		saveWeatherHistory(ctx, db, weather)
+	}
 	createWeatherWebhooks(oldWeather, weather)
 }
+
+// saveWeatherHistory records a change of condition for the weather history api
+func saveWeatherHistory(ctx context.Context, db db.DbDetails, weather *Weather) {
+	_, err := db.GeneralDb.NamedExecContext(ctx,
+		"INSERT INTO weather_history (id, changed, gameplay_condition, severity, warn_weather) "+
+			"VALUES (:id, :updated, :gameplay_condition, :severity, :warn_weather) "+
+			"ON DUPLICATE KEY UPDATE gameplay_condition = VALUES(gameplay_condition), severity = VALUES(severity), warn_weather = VALUES(warn_weather)",
+		weather)
+	statsCollector.IncDbQuery("insert weather_history", err)
+	if err != nil {
+		log.Errorf("insert weather_history: %s", err)
+	}
+}
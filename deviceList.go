@@ -1,54 +1,353 @@
 package main
 
 import (
-	"golbat/config"
+	"context"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/jellydator/ttlcache/v3"
+	stripedmutex "github.com/nmvalera/striped-mutex"
+	"github.com/puzpuzpuz/xsync/v2"
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	db2 "golbat/db"
+	"golbat/decoder"
+	"golbat/geo"
+	"golbat/webhooks"
 )
 
-type DeviceLocation struct {
-	Latitude    float64
-	Longitude   float64
-	LastUpdate  int64
-	ScanContext string
+type deviceWebhooksSenderInterface interface {
+	AddMessage(whType webhooks.WebhookType, message any, areas []geo.AreaName)
+}
+
+// deviceState is a device as held in memory. Devices are written back to the database
+// every minute, and loaded again on start. A device is only read or changed while holding
+// its lock from deviceStripedMutex.
+type deviceState struct {
+	db2.Device
+	areas         []geo.AreaName
+	assignedAreas []geo.AreaName
+	minute        int64
+	minuteProtos  int
+	dirty         bool
+}
+
+const deviceBatchSize = 500
+
+var deviceStripedMutex = stripedmutex.New(128)
+var devices *xsync.MapOf[string, *deviceState]
+var deviceWebhooksSender deviceWebhooksSenderInterface
+
+func deviceExpiry() time.Duration {
+	return time.Hour * time.Duration(config.Config.Cleanup.DeviceHours)
+}
+
+// InitDeviceCache loads the devices seen within the cleanup period from the database
+func InitDeviceCache(whSender deviceWebhooksSenderInterface) {
+	deviceWebhooksSender = whSender
+	loaded := xsync.NewMapOf[*deviceState]()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	stored, err := db2.LoadDevices(ctx, dbDetails, time.Now().Add(-deviceExpiry()).Unix())
+	if err != nil {
+		log.Errorf("Failed to load devices: %s", err)
+	}
+	for _, device := range stored {
+		loaded.Store(device.Id, &deviceState{
+			Device:        device,
+			areas:         config.AreaNamesFromStrings(splitAreaList(device.Areas)),
+			assignedAreas: config.AreaNamesFromStrings(splitAreaList(device.AssignedAreas)),
+		})
+	}
+	log.Infof("Loaded %d devices", loaded.Size())
+
+	devices = loaded
 }
 
-var deviceLocation *ttlcache.Cache[string, DeviceLocation]
+// StartDeviceMonitor saves devices, checks for devices which have gone silent and removes
+// expired devices once a minute until ctx is cancelled
+func StartDeviceMonitor(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
 
-func InitDeviceCache() {
-	deviceLocation = ttlcache.New[string, DeviceLocation](
-		ttlcache.WithTTL[string, DeviceLocation](time.Hour * time.Duration(config.Config.Cleanup.DeviceHours)),
-	)
-	go deviceLocation.Start()
+		for {
+			select {
+			case <-ctx.Done():
+				saveDevices()
+				return
+			case <-ticker.C:
+				checkDevices()
+				saveDevices()
+			}
+		}
+	}()
+}
+
+// lockDevice takes the lock for a device, returning the function to release it
+func lockDevice(deviceId string) func() {
+	deviceMutex, _ := deviceStripedMutex.GetLock(deviceId)
+	deviceMutex.Lock()
+	return deviceMutex.Unlock
 }
 
-func UpdateDeviceLocation(deviceId string, lat, lon float64, scanContext string) {
-	deviceLocation.Set(deviceId, DeviceLocation{
-		Latitude:    lat,
-		Longitude:   lon,
-		LastUpdate:  time.Now().Unix(),
-		ScanContext: scanContext,
-	}, time.Hour*time.Duration(config.Config.Cleanup.DeviceHours))
+// getDevice returns the device, creating it if not seen before. The device lock must be held.
+func getDevice(deviceId string, now int64) *deviceState {
+	device, _ := devices.LoadOrCompute(deviceId, func() *deviceState {
+		return &deviceState{Device: db2.Device{Id: deviceId, FirstSeen: now}}
+	})
+	return device
+}
+
+// touch records that the device has been heard from. The device lock must be held.
+func (device *deviceState) touch(now int64) {
+	device.LastUpdate = now
+	device.dirty = true
+	if device.Silent {
+		device.Silent = false
+		createDeviceWebhook(device, "resumed")
+	}
+}
+
+func UpdateDeviceLocation(deviceId string, lat, lon float64, scanContext string, account string) {
+	if devices == nil {
+		return
+	}
+	areas := decoder.MatchStatsGeofence(lat, lon)
+	now := time.Now().Unix()
+
+	defer lockDevice(deviceId)()
+
+	device := getDevice(deviceId, now)
+	device.Lat = lat
+	device.Lon = lon
+	device.ScanContext = scanContext
+	if account != "" {
+		device.Account = account
+	}
+	device.areas = areas
+	device.Areas = joinAreaList(areas)
+	device.touch(now)
+
+	// A device is assigned to the areas it is first seen in, unless assigned through the api
+	if len(device.assignedAreas) == 0 {
+		if len(areas) > 0 {
+			device.assignedAreas = areas
+			device.AssignedAreas = device.Areas
+		}
+		return
+	}
+
+	inArea := geo.AreaMatchWithWildcards(areas, device.assignedAreas)
+	if !inArea && !device.OutOfArea {
+		device.OutOfArea = true
+		createDeviceWebhook(device, "left_area")
+	} else if inArea && device.OutOfArea {
+		device.OutOfArea = false
+		createDeviceWebhook(device, "returned_area")
+	}
+}
+
+// RecordDeviceProto counts a proto received from a device
+func RecordDeviceProto(deviceId string, account string, method string) {
+	if devices == nil || deviceId == "" {
+		return
+	}
+	now := time.Now().Unix()
+
+	defer lockDevice(deviceId)()
+
+	device := getDevice(deviceId, now)
+	device.rollMinute(now)
+	device.minuteProtos++
+	device.LastMethod = method
+	if account != "" {
+		device.Account = account
+	}
+	device.touch(now)
+}
+
+// rollMinute moves the proto count on to a new minute, keeping the count for the last
+// full minute as the device's rate
+func (device *deviceState) rollMinute(now int64) {
+	minute := now / 60
+	if minute == device.minute {
+		return
+	}
+	if minute == device.minute+1 {
+		device.ProtosPerMinute = device.minuteProtos
+	} else {
+		device.ProtosPerMinute = 0
+	}
+	device.minute = minute
+	device.minuteProtos = 0
+}
+
+func checkDevices() {
+	now := time.Now().Unix()
+	silentAfter := int64(config.Config.Tuning.DeviceSilentMins) * 60
+	expireBefore := now - int64(deviceExpiry().Seconds())
+
+	devices.Range(func(deviceId string, device *deviceState) bool {
+		unlock := lockDevice(deviceId)
+		defer unlock()
+
+		if device.LastUpdate < expireBefore {
+			devices.Delete(deviceId)
+			return true
+		}
+		device.rollMinute(now)
+		if silentAfter > 0 && !device.Silent && now-device.LastUpdate > silentAfter {
+			device.Silent = true
+			device.dirty = true
+			createDeviceWebhook(device, "silent")
+		}
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := db2.DeleteDevicesBefore(ctx, dbDetails, expireBefore); err != nil {
+		log.Errorf("Failed to remove expired devices: %s", err)
+	}
+}
+
+func saveDevices() {
+	var changed []db2.Device
+	devices.Range(func(deviceId string, device *deviceState) bool {
+		unlock := lockDevice(deviceId)
+		defer unlock()
+
+		if !device.dirty {
+			return true
+		}
+		device.dirty = false
+		// an id too long for the table cannot be stored, and would fail the whole batch
+		if len(device.Id) > 100 {
+			log.Warnf("Device %.100s...: id too long to be saved", device.Id)
+			return true
+		}
+		changed = append(changed, truncateDevice(device.Device))
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for i := 0; i < len(changed); i += deviceBatchSize {
+		end := min(i+deviceBatchSize, len(changed))
+		if err := db2.SaveDevices(ctx, dbDetails, changed[i:end]); err != nil {
+			log.Errorf("Failed to save %d devices: %s", end-i, err)
+		}
+	}
+}
+
+// truncateDevice shortens the text fields of a device to fit the device table
+func truncateDevice(device db2.Device) db2.Device {
+	device.ScanContext = truncateString(device.ScanContext, 100)
+	device.Account = truncateString(device.Account, 100)
+	device.Areas = truncateString(device.Areas, 1024)
+	device.AssignedAreas = truncateString(device.AssignedAreas, 1024)
+	device.LastMethod = truncateString(device.LastMethod, 100)
+	return device
+}
+
+// SetDeviceAssignedAreas replaces the areas a device is expected to stay within, returning
+// false if the device is unknown
+func SetDeviceAssignedAreas(deviceId string, areaNames []string) bool {
+	defer lockDevice(deviceId)()
+
+	device, found := devices.Load(deviceId)
+	if !found {
+		return false
+	}
+	device.assignedAreas = config.AreaNamesFromStrings(areaNames)
+	device.AssignedAreas = strings.Join(areaNames, ",")
+	device.OutOfArea = len(device.assignedAreas) > 0 && !geo.AreaMatchWithWildcards(device.areas, device.assignedAreas)
+	device.dirty = true
+	return true
+}
+
+func createDeviceWebhook(device *deviceState, event string) {
+	if deviceWebhooksSender == nil {
+		return
+	}
+	deviceHook := map[string]interface{}{
+		"uuid":              device.Id,
+		"event":             event,
+		"latitude":          device.Lat,
+		"longitude":         device.Lon,
+		"scan_context":      device.ScanContext,
+		"account":           device.Account,
+		"areas":             splitAreaList(device.Areas),
+		"assigned_areas":    splitAreaList(device.AssignedAreas),
+		"last_method":       device.LastMethod,
+		"protos_per_minute": device.ProtosPerMinute,
+		"last_update":       device.LastUpdate,
+	}
+	areas := device.areas
+	if len(areas) == 0 {
+		areas = device.assignedAreas
+	}
+	deviceWebhooksSender.AddMessage(webhooks.Device, deviceHook, areas)
+}
+
+func joinAreaList(areas []geo.AreaName) string {
+	names := make([]string, 0, len(areas))
+	for i := range areas {
+		names = append(names, areas[i].String())
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func splitAreaList(areas string) []string {
+	if areas == "" {
+		return []string{}
+	}
+	return strings.Split(areas, ",")
 }
 
 type ApiDeviceLocation struct {
-	Latitude    float64 `json:"latitude"`
-	Longitude   float64 `json:"longitude"`
-	LastUpdate  int64   `json:"last_update"`
-	ScanContext string  `json:"scan_context"`
+	Latitude        float64  `json:"latitude"`
+	Longitude       float64  `json:"longitude"`
+	LastUpdate      int64    `json:"last_update"`
+	ScanContext     string   `json:"scan_context"`
+	Account         string   `json:"account"`
+	Areas           []string `json:"areas"`
+	AssignedAreas   []string `json:"assigned_areas"`
+	LastMethod      string   `json:"last_method"`
+	ProtosPerMinute int      `json:"protos_per_minute"`
+	Silent          bool     `json:"silent"`
+	OutOfArea       bool     `json:"out_of_area"`
+	FirstSeen       int64    `json:"first_seen"`
 }
 
 func GetAllDevices() map[string]ApiDeviceLocation {
 	locations := map[string]ApiDeviceLocation{}
-	for _, key := range deviceLocation.Items() {
-		deviceLocation := key.Value()
-		locations[key.Key()] = ApiDeviceLocation{
-			Latitude:    deviceLocation.Latitude,
-			Longitude:   deviceLocation.Longitude,
-			LastUpdate:  deviceLocation.LastUpdate,
-			ScanContext: deviceLocation.ScanContext,
+	devices.Range(func(deviceId string, device *deviceState) bool {
+		unlock := lockDevice(deviceId)
+		defer unlock()
+
+		locations[deviceId] = ApiDeviceLocation{
+			Latitude:        device.Lat,
+			Longitude:       device.Lon,
+			LastUpdate:      device.LastUpdate,
+			ScanContext:     device.ScanContext,
+			Account:         device.Account,
+			Areas:           splitAreaList(device.Areas),
+			AssignedAreas:   splitAreaList(device.AssignedAreas),
+			LastMethod:      device.LastMethod,
+			ProtosPerMinute: device.ProtosPerMinute,
+			Silent:          device.Silent,
+			OutOfArea:       device.OutOfArea,
+			FirstSeen:       device.FirstSeen,
 		}
-	}
+		return true
+	})
 	return locations
 }
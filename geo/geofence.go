@@ -249,6 +249,38 @@ func MatchGeofencesRtree(tree *rtree.RTreeG[*geojson.Feature], lat, lon float64)
 	return
 }
 
+// AreaBoundsRtree returns the bounding box of the features in the tree matching any of the
+// area names, which may include wildcards, or false if none match
+func AreaBoundsRtree(tree *rtree.RTreeG[*geojson.Feature], areaNames []AreaName) (BoundingBox, bool) {
+	if tree == nil {
+		return BoundingBox{}, false
+	}
+
+	var bound orb.Bound
+	found := false
+	tree.Scan(func(min, max [2]float64, f *geojson.Feature) bool {
+		name := f.Properties.MustString("name", "unknown")
+		parent := f.Properties.MustString("parent", name)
+		if !AreaMatchWithWildcards([]AreaName{{Parent: parent, Name: name}}, areaNames) {
+			return true
+		}
+		if found {
+			bound = bound.Union(f.Geometry.Bound())
+		} else {
+			bound = f.Geometry.Bound()
+			found = true
+		}
+		return true
+	})
+
+	return BoundingBox{
+		MinimumLatitude:  bound.Min.Lat(),
+		MinimumLongitude: bound.Min.Lon(),
+		MaximumLatitude:  bound.Max.Lat(),
+		MaximumLongitude: bound.Max.Lon(),
+	}, found
+}
+
 func MatchGeofences(featureCollection *geojson.FeatureCollection, lat, lon float64) (areas []AreaName) {
 	if featureCollection == nil {
 		return
@@ -66,6 +66,52 @@ func (PokemonScanResponse_Status) EnumDescriptor() ([]byte, []int) {
 	return file_grpc_pokemon_api_proto_rawDescGZIP(), []int{4, 0}
 }
 
+type GymScanResponse_Status int32
+
+const (
+	GymScanResponse_UNSET   GymScanResponse_Status = 0
+	GymScanResponse_SUCCESS GymScanResponse_Status = 200
+)
+
+// Enum value maps for GymScanResponse_Status.
+var (
+	GymScanResponse_Status_name = map[int32]string{
+		0:   "UNSET",
+		200: "SUCCESS",
+	}
+	GymScanResponse_Status_value = map[string]int32{
+		"UNSET":   0,
+		"SUCCESS": 200,
+	}
+)
+
+func (x GymScanResponse_Status) Enum() *GymScanResponse_Status {
+	p := new(GymScanResponse_Status)
+	*p = x
+	return p
+}
+
+func (x GymScanResponse_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GymScanResponse_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_grpc_pokemon_api_proto_enumTypes[1].Descriptor()
+}
+
+func (GymScanResponse_Status) Type() protoreflect.EnumType {
+	return &file_grpc_pokemon_api_proto_enumTypes[1]
+}
+
+func (x GymScanResponse_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GymScanResponse_Status.Descriptor instead.
+func (GymScanResponse_Status) EnumDescriptor() ([]byte, []int) {
+	return file_grpc_pokemon_api_proto_rawDescGZIP(), []int{8, 0}
+}
+
 type PokemonScanRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -796,6 +842,514 @@ func (x *PokemonDetails) GetDistance() float32 {
 	return 0
 }
 
+type GymScanRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MinLat  float32   `protobuf:"fixed32,1,opt,name=min_lat,json=minLat,proto3" json:"min_lat,omitempty"`
+	MinLon  float32   `protobuf:"fixed32,2,opt,name=min_lon,json=minLon,proto3" json:"min_lon,omitempty"`
+	MaxLat  float32   `protobuf:"fixed32,3,opt,name=max_lat,json=maxLat,proto3" json:"max_lat,omitempty"`
+	MaxLon  float32   `protobuf:"fixed32,4,opt,name=max_lon,json=maxLon,proto3" json:"max_lon,omitempty"`
+	Limit   int32     `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Filters []*GymDnf `protobuf:"bytes,7,rep,name=filters,proto3" json:"filters,omitempty"`
+}
+
+func (x *GymScanRequest) Reset() {
+	*x = GymScanRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_pokemon_api_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GymScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GymScanRequest) ProtoMessage() {}
+
+func (x *GymScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_pokemon_api_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GymScanRequest.ProtoReflect.Descriptor instead.
+func (*GymScanRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_pokemon_api_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GymScanRequest) GetMinLat() float32 {
+	if x != nil {
+		return x.MinLat
+	}
+	return 0
+}
+
+func (x *GymScanRequest) GetMinLon() float32 {
+	if x != nil {
+		return x.MinLon
+	}
+	return 0
+}
+
+func (x *GymScanRequest) GetMaxLat() float32 {
+	if x != nil {
+		return x.MaxLat
+	}
+	return 0
+}
+
+func (x *GymScanRequest) GetMaxLon() float32 {
+	if x != nil {
+		return x.MaxLon
+	}
+	return 0
+}
+
+func (x *GymScanRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GymScanRequest) GetFilters() []*GymDnf {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type GymDnf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Team           []int32      `protobuf:"varint,1,rep,packed,name=team,proto3" json:"team,omitempty"`
+	AvailableSlots *RangeMinMax `protobuf:"bytes,2,opt,name=available_slots,json=availableSlots,proto3,oneof" json:"available_slots,omitempty"`
+	RaidLevel      *RangeMinMax `protobuf:"bytes,3,opt,name=raid_level,json=raidLevel,proto3,oneof" json:"raid_level,omitempty"`
+	RaidPokemon    []*PokemonId `protobuf:"bytes,4,rep,name=raid_pokemon,json=raidPokemon,proto3" json:"raid_pokemon,omitempty"`
+	ExRaidEligible *bool        `protobuf:"varint,5,opt,name=ex_raid_eligible,json=exRaidEligible,proto3,oneof" json:"ex_raid_eligible,omitempty"`
+	InBattle       *bool        `protobuf:"varint,6,opt,name=in_battle,json=inBattle,proto3,oneof" json:"in_battle,omitempty"`
+	PowerUpLevel   *RangeMinMax `protobuf:"bytes,7,opt,name=power_up_level,json=powerUpLevel,proto3,oneof" json:"power_up_level,omitempty"`
+}
+
+func (x *GymDnf) Reset() {
+	*x = GymDnf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_pokemon_api_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GymDnf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GymDnf) ProtoMessage() {}
+
+func (x *GymDnf) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_pokemon_api_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GymDnf.ProtoReflect.Descriptor instead.
+func (*GymDnf) Descriptor() ([]byte, []int) {
+	return file_grpc_pokemon_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GymDnf) GetTeam() []int32 {
+	if x != nil {
+		return x.Team
+	}
+	return nil
+}
+
+func (x *GymDnf) GetAvailableSlots() *RangeMinMax {
+	if x != nil {
+		return x.AvailableSlots
+	}
+	return nil
+}
+
+func (x *GymDnf) GetRaidLevel() *RangeMinMax {
+	if x != nil {
+		return x.RaidLevel
+	}
+	return nil
+}
+
+func (x *GymDnf) GetRaidPokemon() []*PokemonId {
+	if x != nil {
+		return x.RaidPokemon
+	}
+	return nil
+}
+
+func (x *GymDnf) GetExRaidEligible() bool {
+	if x != nil && x.ExRaidEligible != nil {
+		return *x.ExRaidEligible
+	}
+	return false
+}
+
+func (x *GymDnf) GetInBattle() bool {
+	if x != nil && x.InBattle != nil {
+		return *x.InBattle
+	}
+	return false
+}
+
+func (x *GymDnf) GetPowerUpLevel() *RangeMinMax {
+	if x != nil {
+		return x.PowerUpLevel
+	}
+	return nil
+}
+
+type GymScanResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status GymScanResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=pokemon_api.GymScanResponse_Status" json:"status,omitempty"`
+	Gyms   []*GymDetails          `protobuf:"bytes,2,rep,name=gyms,proto3" json:"gyms,omitempty"`
+}
+
+func (x *GymScanResponse) Reset() {
+	*x = GymScanResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_pokemon_api_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GymScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GymScanResponse) ProtoMessage() {}
+
+func (x *GymScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_pokemon_api_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GymScanResponse.ProtoReflect.Descriptor instead.
+func (*GymScanResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_pokemon_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GymScanResponse) GetStatus() GymScanResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return GymScanResponse_UNSET
+}
+
+func (x *GymScanResponse) GetGyms() []*GymDetails {
+	if x != nil {
+		return x.Gyms
+	}
+	return nil
+}
+
+type GymDetails struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                   string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Lat                  float64 `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon                  float64 `protobuf:"fixed64,3,opt,name=lon,proto3" json:"lon,omitempty"`
+	Name                 *string `protobuf:"bytes,4,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Url                  *string `protobuf:"bytes,5,opt,name=url,proto3,oneof" json:"url,omitempty"`
+	TeamId               *int32  `protobuf:"varint,6,opt,name=team_id,json=teamId,proto3,oneof" json:"team_id,omitempty"`
+	AvailableSlots       *int32  `protobuf:"varint,7,opt,name=available_slots,json=availableSlots,proto3,oneof" json:"available_slots,omitempty"`
+	GuardingPokemonId    *int32  `protobuf:"varint,8,opt,name=guarding_pokemon_id,json=guardingPokemonId,proto3,oneof" json:"guarding_pokemon_id,omitempty"`
+	TotalCp              *int32  `protobuf:"varint,9,opt,name=total_cp,json=totalCp,proto3,oneof" json:"total_cp,omitempty"`
+	InBattle             bool    `protobuf:"varint,10,opt,name=in_battle,json=inBattle,proto3" json:"in_battle,omitempty"`
+	ExRaidEligible       bool    `protobuf:"varint,11,opt,name=ex_raid_eligible,json=exRaidEligible,proto3" json:"ex_raid_eligible,omitempty"`
+	ArScanEligible       bool    `protobuf:"varint,12,opt,name=ar_scan_eligible,json=arScanEligible,proto3" json:"ar_scan_eligible,omitempty"`
+	PowerUpLevel         *int32  `protobuf:"varint,13,opt,name=power_up_level,json=powerUpLevel,proto3,oneof" json:"power_up_level,omitempty"`
+	PowerUpPoints        *int32  `protobuf:"varint,14,opt,name=power_up_points,json=powerUpPoints,proto3,oneof" json:"power_up_points,omitempty"`
+	PowerUpEndTimestamp  *int64  `protobuf:"varint,15,opt,name=power_up_end_timestamp,json=powerUpEndTimestamp,proto3,oneof" json:"power_up_end_timestamp,omitempty"`
+	RaidLevel            *int32  `protobuf:"varint,16,opt,name=raid_level,json=raidLevel,proto3,oneof" json:"raid_level,omitempty"`
+	RaidSpawnTimestamp   *int64  `protobuf:"varint,17,opt,name=raid_spawn_timestamp,json=raidSpawnTimestamp,proto3,oneof" json:"raid_spawn_timestamp,omitempty"`
+	RaidBattleTimestamp  *int64  `protobuf:"varint,18,opt,name=raid_battle_timestamp,json=raidBattleTimestamp,proto3,oneof" json:"raid_battle_timestamp,omitempty"`
+	RaidEndTimestamp     *int64  `protobuf:"varint,19,opt,name=raid_end_timestamp,json=raidEndTimestamp,proto3,oneof" json:"raid_end_timestamp,omitempty"`
+	RaidPokemonId        *int32  `protobuf:"varint,20,opt,name=raid_pokemon_id,json=raidPokemonId,proto3,oneof" json:"raid_pokemon_id,omitempty"`
+	RaidPokemonForm      *int32  `protobuf:"varint,21,opt,name=raid_pokemon_form,json=raidPokemonForm,proto3,oneof" json:"raid_pokemon_form,omitempty"`
+	RaidPokemonCostume   *int32  `protobuf:"varint,22,opt,name=raid_pokemon_costume,json=raidPokemonCostume,proto3,oneof" json:"raid_pokemon_costume,omitempty"`
+	RaidPokemonGender    *int32  `protobuf:"varint,23,opt,name=raid_pokemon_gender,json=raidPokemonGender,proto3,oneof" json:"raid_pokemon_gender,omitempty"`
+	RaidPokemonAlignment *int32  `protobuf:"varint,24,opt,name=raid_pokemon_alignment,json=raidPokemonAlignment,proto3,oneof" json:"raid_pokemon_alignment,omitempty"`
+	RaidPokemonCp        *int32  `protobuf:"varint,25,opt,name=raid_pokemon_cp,json=raidPokemonCp,proto3,oneof" json:"raid_pokemon_cp,omitempty"`
+	RaidPokemonMove_1    *int32  `protobuf:"varint,26,opt,name=raid_pokemon_move_1,json=raidPokemonMove1,proto3,oneof" json:"raid_pokemon_move_1,omitempty"`
+	RaidPokemonMove_2    *int32  `protobuf:"varint,27,opt,name=raid_pokemon_move_2,json=raidPokemonMove2,proto3,oneof" json:"raid_pokemon_move_2,omitempty"`
+	RaidIsExclusive      bool    `protobuf:"varint,28,opt,name=raid_is_exclusive,json=raidIsExclusive,proto3" json:"raid_is_exclusive,omitempty"`
+	Updated              int64   `protobuf:"varint,29,opt,name=updated,proto3" json:"updated,omitempty"`
+}
+
+func (x *GymDetails) Reset() {
+	*x = GymDetails{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_pokemon_api_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GymDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GymDetails) ProtoMessage() {}
+
+func (x *GymDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_pokemon_api_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GymDetails.ProtoReflect.Descriptor instead.
+func (*GymDetails) Descriptor() ([]byte, []int) {
+	return file_grpc_pokemon_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GymDetails) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GymDetails) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *GymDetails) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *GymDetails) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *GymDetails) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *GymDetails) GetTeamId() int32 {
+	if x != nil && x.TeamId != nil {
+		return *x.TeamId
+	}
+	return 0
+}
+
+func (x *GymDetails) GetAvailableSlots() int32 {
+	if x != nil && x.AvailableSlots != nil {
+		return *x.AvailableSlots
+	}
+	return 0
+}
+
+func (x *GymDetails) GetGuardingPokemonId() int32 {
+	if x != nil && x.GuardingPokemonId != nil {
+		return *x.GuardingPokemonId
+	}
+	return 0
+}
+
+func (x *GymDetails) GetTotalCp() int32 {
+	if x != nil && x.TotalCp != nil {
+		return *x.TotalCp
+	}
+	return 0
+}
+
+func (x *GymDetails) GetInBattle() bool {
+	if x != nil {
+		return x.InBattle
+	}
+	return false
+}
+
+func (x *GymDetails) GetExRaidEligible() bool {
+	if x != nil {
+		return x.ExRaidEligible
+	}
+	return false
+}
+
+func (x *GymDetails) GetArScanEligible() bool {
+	if x != nil {
+		return x.ArScanEligible
+	}
+	return false
+}
+
+func (x *GymDetails) GetPowerUpLevel() int32 {
+	if x != nil && x.PowerUpLevel != nil {
+		return *x.PowerUpLevel
+	}
+	return 0
+}
+
+func (x *GymDetails) GetPowerUpPoints() int32 {
+	if x != nil && x.PowerUpPoints != nil {
+		return *x.PowerUpPoints
+	}
+	return 0
+}
+
+func (x *GymDetails) GetPowerUpEndTimestamp() int64 {
+	if x != nil && x.PowerUpEndTimestamp != nil {
+		return *x.PowerUpEndTimestamp
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidLevel() int32 {
+	if x != nil && x.RaidLevel != nil {
+		return *x.RaidLevel
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidSpawnTimestamp() int64 {
+	if x != nil && x.RaidSpawnTimestamp != nil {
+		return *x.RaidSpawnTimestamp
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidBattleTimestamp() int64 {
+	if x != nil && x.RaidBattleTimestamp != nil {
+		return *x.RaidBattleTimestamp
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidEndTimestamp() int64 {
+	if x != nil && x.RaidEndTimestamp != nil {
+		return *x.RaidEndTimestamp
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonId() int32 {
+	if x != nil && x.RaidPokemonId != nil {
+		return *x.RaidPokemonId
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonForm() int32 {
+	if x != nil && x.RaidPokemonForm != nil {
+		return *x.RaidPokemonForm
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonCostume() int32 {
+	if x != nil && x.RaidPokemonCostume != nil {
+		return *x.RaidPokemonCostume
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonGender() int32 {
+	if x != nil && x.RaidPokemonGender != nil {
+		return *x.RaidPokemonGender
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonAlignment() int32 {
+	if x != nil && x.RaidPokemonAlignment != nil {
+		return *x.RaidPokemonAlignment
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonCp() int32 {
+	if x != nil && x.RaidPokemonCp != nil {
+		return *x.RaidPokemonCp
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonMove_1() int32 {
+	if x != nil && x.RaidPokemonMove_1 != nil {
+		return *x.RaidPokemonMove_1
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidPokemonMove_2() int32 {
+	if x != nil && x.RaidPokemonMove_2 != nil {
+		return *x.RaidPokemonMove_2
+	}
+	return 0
+}
+
+func (x *GymDetails) GetRaidIsExclusive() bool {
+	if x != nil {
+		return x.RaidIsExclusive
+	}
+	return false
+}
+
+func (x *GymDetails) GetUpdated() int64 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
 var File_grpc_pokemon_api_proto protoreflect.FileDescriptor
 
 var file_grpc_pokemon_api_proto_rawDesc = []byte{
@@ -990,15 +1544,173 @@ var file_grpc_pokemon_api_proto_rawDesc = []byte{
 	0x65, 0x5f, 0x31, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x5f,
 	0x32, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x33, 0x42,
 	0x06, 0x0a, 0x04, 0x5f, 0x70, 0x76, 0x70, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x64, 0x69, 0x73, 0x74,
-	0x61, 0x6e, 0x63, 0x65, 0x32, 0x58, 0x0a, 0x07, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x12,
-	0x4d, 0x0a, 0x06, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x1f, 0x2e, 0x70, 0x6f, 0x6b, 0x65,
-	0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x53,
-	0x63, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x70, 0x6f, 0x6b,
-	0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e,
-	0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x22,
-	0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x75, 0x6e, 0x6f,
-	0x77, 0x6e, 0x68, 0x61, 0x73, 0x68, 0x2f, 0x67, 0x6f, 0x6c, 0x62, 0x61, 0x74, 0x2f, 0x67, 0x72,
-	0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x61, 0x6e, 0x63, 0x65, 0x22, 0xb9, 0x01, 0x0a, 0x0e, 0x47, 0x79, 0x6d, 0x53, 0x63, 0x61, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x6c,
+	0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4c, 0x61, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x6c, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x02, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4c, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78,
+	0x5f, 0x6c, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x4c,
+	0x61, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x6f, 0x6e, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x02, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x4c, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x2d, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69,
+	0x2e, 0x47, 0x79, 0x6d, 0x44, 0x6e, 0x66, 0x52, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73,
+	0x22, 0xcc, 0x03, 0x0a, 0x06, 0x47, 0x79, 0x6d, 0x44, 0x6e, 0x66, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x04, 0x74, 0x65, 0x61, 0x6d, 0x12,
+	0x46, 0x0a, 0x0f, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x6c, 0x6f,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d,
+	0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x69, 0x6e, 0x4d,
+	0x61, 0x78, 0x48, 0x00, 0x52, 0x0e, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x53,
+	0x6c, 0x6f, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x3c, 0x0a, 0x0a, 0x72, 0x61, 0x69, 0x64, 0x5f,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x6f,
+	0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x4d,
+	0x69, 0x6e, 0x4d, 0x61, 0x78, 0x48, 0x01, 0x52, 0x09, 0x72, 0x61, 0x69, 0x64, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x39, 0x0a, 0x0c, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f,
+	0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6f,
+	0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f,
+	0x6e, 0x49, 0x64, 0x52, 0x0b, 0x72, 0x61, 0x69, 0x64, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e,
+	0x12, 0x2d, 0x0a, 0x10, 0x65, 0x78, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x65, 0x6c, 0x69, 0x67,
+	0x69, 0x62, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x48, 0x02, 0x52, 0x0e, 0x65, 0x78,
+	0x52, 0x61, 0x69, 0x64, 0x45, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12,
+	0x20, 0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x6c, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x08, 0x48, 0x03, 0x52, 0x08, 0x69, 0x6e, 0x42, 0x61, 0x74, 0x74, 0x6c, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x43, 0x0a, 0x0e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x70, 0x5f, 0x6c, 0x65,
+	0x76, 0x65, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x6f, 0x6b, 0x65,
+	0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x69, 0x6e,
+	0x4d, 0x61, 0x78, 0x48, 0x04, 0x52, 0x0c, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x70, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x61, 0x76, 0x61, 0x69, 0x6c,
+	0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x72,
+	0x61, 0x69, 0x64, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x65, 0x78,
+	0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x65, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x42, 0x0c,
+	0x0a, 0x0a, 0x5f, 0x69, 0x6e, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x6c, 0x65, 0x42, 0x11, 0x0a, 0x0f,
+	0x5f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x70, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22,
+	0x9e, 0x01, 0x0a, 0x0f, 0x47, 0x79, 0x6d, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70,
+	0x69, 0x2e, 0x47, 0x79, 0x6d, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x2b, 0x0a, 0x04, 0x67, 0x79, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x79, 0x6d,
+	0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x52, 0x04, 0x67, 0x79, 0x6d, 0x73, 0x22, 0x21, 0x0a,
+	0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54,
+	0x10, 0x00, 0x12, 0x0c, 0x0a, 0x07, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53, 0x10, 0xc8, 0x01,
+	0x22, 0xe6, 0x0c, 0x0a, 0x0a, 0x47, 0x79, 0x6d, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03,
+	0x6c, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03,
+	0x75, 0x72, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x03, 0x75, 0x72, 0x6c,
+	0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x07, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x48, 0x02, 0x52, 0x06, 0x74, 0x65, 0x61, 0x6d, 0x49, 0x64, 0x88, 0x01,
+	0x01, 0x12, 0x2c, 0x0a, 0x0f, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73,
+	0x6c, 0x6f, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52, 0x0e, 0x61, 0x76,
+	0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x53, 0x6c, 0x6f, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12,
+	0x33, 0x0a, 0x13, 0x67, 0x75, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x6f, 0x6b, 0x65,
+	0x6d, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x48, 0x04, 0x52, 0x11,
+	0x67, 0x75, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x49,
+	0x64, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x08, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x70,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x48, 0x05, 0x52, 0x07, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43,
+	0x70, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x6c,
+	0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x6e, 0x42, 0x61, 0x74, 0x74, 0x6c,
+	0x65, 0x12, 0x28, 0x0a, 0x10, 0x65, 0x78, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x65, 0x6c, 0x69,
+	0x67, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x65, 0x78, 0x52,
+	0x61, 0x69, 0x64, 0x45, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x61,
+	0x72, 0x5f, 0x73, 0x63, 0x61, 0x6e, 0x5f, 0x65, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x61, 0x72, 0x53, 0x63, 0x61, 0x6e, 0x45, 0x6c, 0x69,
+	0x67, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x29, 0x0a, 0x0e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75,
+	0x70, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x48, 0x06, 0x52,
+	0x0c, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x70, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x88, 0x01, 0x01,
+	0x12, 0x2b, 0x0a, 0x0f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x70, 0x5f, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x48, 0x07, 0x52, 0x0d, 0x70, 0x6f, 0x77,
+	0x65, 0x72, 0x55, 0x70, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x38, 0x0a,
+	0x16, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x70, 0x5f, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x48, 0x08, 0x52,
+	0x13, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x70, 0x45, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x72, 0x61, 0x69, 0x64, 0x5f,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x48, 0x09, 0x52, 0x09, 0x72,
+	0x61, 0x69, 0x64, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x35, 0x0a, 0x14, 0x72,
+	0x61, 0x69, 0x64, 0x5f, 0x73, 0x70, 0x61, 0x77, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x18, 0x11, 0x20, 0x01, 0x28, 0x03, 0x48, 0x0a, 0x52, 0x12, 0x72, 0x61, 0x69,
+	0x64, 0x53, 0x70, 0x61, 0x77, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x88,
+	0x01, 0x01, 0x12, 0x37, 0x0a, 0x15, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x6c,
+	0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x12, 0x20, 0x01, 0x28,
+	0x03, 0x48, 0x0b, 0x52, 0x13, 0x72, 0x61, 0x69, 0x64, 0x42, 0x61, 0x74, 0x74, 0x6c, 0x65, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x12, 0x72,
+	0x61, 0x69, 0x64, 0x5f, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x18, 0x13, 0x20, 0x01, 0x28, 0x03, 0x48, 0x0c, 0x52, 0x10, 0x72, 0x61, 0x69, 0x64, 0x45,
+	0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x88, 0x01, 0x01, 0x12, 0x2b,
+	0x0a, 0x0f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x69,
+	0x64, 0x18, 0x14, 0x20, 0x01, 0x28, 0x05, 0x48, 0x0d, 0x52, 0x0d, 0x72, 0x61, 0x69, 0x64, 0x50,
+	0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x11, 0x72,
+	0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x66, 0x6f, 0x72, 0x6d,
+	0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x48, 0x0e, 0x52, 0x0f, 0x72, 0x61, 0x69, 0x64, 0x50, 0x6f,
+	0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x46, 0x6f, 0x72, 0x6d, 0x88, 0x01, 0x01, 0x12, 0x35, 0x0a, 0x14,
+	0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x73,
+	0x74, 0x75, 0x6d, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05, 0x48, 0x0f, 0x52, 0x12, 0x72, 0x61,
+	0x69, 0x64, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x43, 0x6f, 0x73, 0x74, 0x75, 0x6d, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x13, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65,
+	0x6d, 0x6f, 0x6e, 0x5f, 0x67, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x17, 0x20, 0x01, 0x28, 0x05,
+	0x48, 0x10, 0x52, 0x11, 0x72, 0x61, 0x69, 0x64, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x47,
+	0x65, 0x6e, 0x64, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x39, 0x0a, 0x16, 0x72, 0x61, 0x69, 0x64,
+	0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65,
+	0x6e, 0x74, 0x18, 0x18, 0x20, 0x01, 0x28, 0x05, 0x48, 0x11, 0x52, 0x14, 0x72, 0x61, 0x69, 0x64,
+	0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
+	0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65,
+	0x6d, 0x6f, 0x6e, 0x5f, 0x63, 0x70, 0x18, 0x19, 0x20, 0x01, 0x28, 0x05, 0x48, 0x12, 0x52, 0x0d,
+	0x72, 0x61, 0x69, 0x64, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x43, 0x70, 0x88, 0x01, 0x01,
+	0x12, 0x32, 0x0a, 0x13, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e,
+	0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x31, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x05, 0x48, 0x13, 0x52,
+	0x10, 0x72, 0x61, 0x69, 0x64, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x4d, 0x6f, 0x76, 0x65,
+	0x31, 0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x13, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b,
+	0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x32, 0x18, 0x1b, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x14, 0x52, 0x10, 0x72, 0x61, 0x69, 0x64, 0x50, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e,
+	0x4d, 0x6f, 0x76, 0x65, 0x32, 0x88, 0x01, 0x01, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x61, 0x69, 0x64,
+	0x5f, 0x69, 0x73, 0x5f, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x18, 0x1c, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0f, 0x72, 0x61, 0x69, 0x64, 0x49, 0x73, 0x45, 0x78, 0x63, 0x6c, 0x75,
+	0x73, 0x69, 0x76, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18,
+	0x1d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x42, 0x07,
+	0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x75, 0x72, 0x6c, 0x42,
+	0x0a, 0x0a, 0x08, 0x5f, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x42, 0x12, 0x0a, 0x10, 0x5f,
+	0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x42,
+	0x16, 0x0a, 0x14, 0x5f, 0x67, 0x75, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x6f, 0x6b,
+	0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x5f, 0x63, 0x70, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75,
+	0x70, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x70, 0x6f, 0x77, 0x65,
+	0x72, 0x5f, 0x75, 0x70, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x42, 0x19, 0x0a, 0x17, 0x5f,
+	0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x70, 0x5f, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x73,
+	0x70, 0x61, 0x77, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x42, 0x18,
+	0x0a, 0x16, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x6c, 0x65, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x72, 0x61, 0x69,
+	0x64, 0x5f, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x42,
+	0x12, 0x0a, 0x10, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b,
+	0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x72, 0x61,
+	0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x73, 0x74, 0x75,
+	0x6d, 0x65, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65,
+	0x6d, 0x6f, 0x6e, 0x5f, 0x67, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x42, 0x19, 0x0a, 0x17, 0x5f, 0x72,
+	0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x6c, 0x69, 0x67,
+	0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70,
+	0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x63, 0x70, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x72, 0x61,
+	0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x5f,
+	0x31, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x72, 0x61, 0x69, 0x64, 0x5f, 0x70, 0x6f, 0x6b, 0x65, 0x6d,
+	0x6f, 0x6e, 0x5f, 0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x32, 0x32, 0x58, 0x0a, 0x07, 0x50, 0x6f, 0x6b,
+	0x65, 0x6d, 0x6f, 0x6e, 0x12, 0x4d, 0x0a, 0x06, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x1f,
+	0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x6b,
+	0x65, 0x6d, 0x6f, 0x6e, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x20, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f,
+	0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x32, 0x4c, 0x0a, 0x03, 0x47, 0x79, 0x6d, 0x12, 0x45, 0x0a, 0x06, 0x53, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x12, 0x1b, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61,
+	0x70, 0x69, 0x2e, 0x47, 0x79, 0x6d, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x6f, 0x6b, 0x65, 0x6d, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e,
+	0x47, 0x79, 0x6d, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x75, 0x6e, 0x6f, 0x77, 0x6e, 0x68, 0x61, 0x73, 0x68, 0x2f, 0x67, 0x6f, 0x6c, 0x62, 0x61, 0x74,
+	0x2f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1013,40 +1725,54 @@ func file_grpc_pokemon_api_proto_rawDescGZIP() []byte {
 	return file_grpc_pokemon_api_proto_rawDescData
 }
 
-var file_grpc_pokemon_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_grpc_pokemon_api_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_grpc_pokemon_api_proto_goTypes = []interface{}{
+var file_grpc_pokemon_api_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_grpc_pokemon_api_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_grpc_pokemon_api_proto_goTypes = []any{
 	(PokemonScanResponse_Status)(0), // 0: pokemon_api.PokemonScanResponse.Status
-	(*PokemonScanRequest)(nil),      // 1: pokemon_api.PokemonScanRequest
-	(*PokemonDnf)(nil),              // 2: pokemon_api.PokemonDnf
-	(*PokemonId)(nil),               // 3: pokemon_api.PokemonId
-	(*RangeMinMax)(nil),             // 4: pokemon_api.RangeMinMax
-	(*PokemonScanResponse)(nil),     // 5: pokemon_api.PokemonScanResponse
-	(*PokemonDetails)(nil),          // 6: pokemon_api.PokemonDetails
+	(GymScanResponse_Status)(0),     // 1: pokemon_api.GymScanResponse.Status
+	(*PokemonScanRequest)(nil),      // 2: pokemon_api.PokemonScanRequest
+	(*PokemonDnf)(nil),              // 3: pokemon_api.PokemonDnf
+	(*PokemonId)(nil),               // 4: pokemon_api.PokemonId
+	(*RangeMinMax)(nil),             // 5: pokemon_api.RangeMinMax
+	(*PokemonScanResponse)(nil),     // 6: pokemon_api.PokemonScanResponse
+	(*PokemonDetails)(nil),          // 7: pokemon_api.PokemonDetails
+	(*GymScanRequest)(nil),          // 8: pokemon_api.GymScanRequest
+	(*GymDnf)(nil),                  // 9: pokemon_api.GymDnf
+	(*GymScanResponse)(nil),         // 10: pokemon_api.GymScanResponse
+	(*GymDetails)(nil),              // 11: pokemon_api.GymDetails
 }
 var file_grpc_pokemon_api_proto_depIdxs = []int32{
-	2,  // 0: pokemon_api.PokemonScanRequest.filters:type_name -> pokemon_api.PokemonDnf
-	3,  // 1: pokemon_api.PokemonDnf.pokemon:type_name -> pokemon_api.PokemonId
-	4,  // 2: pokemon_api.PokemonDnf.Iv:type_name -> pokemon_api.RangeMinMax
-	4,  // 3: pokemon_api.PokemonDnf.AtkIv:type_name -> pokemon_api.RangeMinMax
-	4,  // 4: pokemon_api.PokemonDnf.DefIv:type_name -> pokemon_api.RangeMinMax
-	4,  // 5: pokemon_api.PokemonDnf.StaIv:type_name -> pokemon_api.RangeMinMax
-	4,  // 6: pokemon_api.PokemonDnf.Level:type_name -> pokemon_api.RangeMinMax
-	4,  // 7: pokemon_api.PokemonDnf.Cp:type_name -> pokemon_api.RangeMinMax
-	4,  // 8: pokemon_api.PokemonDnf.Gender:type_name -> pokemon_api.RangeMinMax
-	4,  // 9: pokemon_api.PokemonDnf.Size:type_name -> pokemon_api.RangeMinMax
-	4,  // 10: pokemon_api.PokemonDnf.PvpLittleRanking:type_name -> pokemon_api.RangeMinMax
-	4,  // 11: pokemon_api.PokemonDnf.PvpGreatRanking:type_name -> pokemon_api.RangeMinMax
-	4,  // 12: pokemon_api.PokemonDnf.PvpUltraRanking:type_name -> pokemon_api.RangeMinMax
+	3,  // 0: pokemon_api.PokemonScanRequest.filters:type_name -> pokemon_api.PokemonDnf
+	4,  // 1: pokemon_api.PokemonDnf.pokemon:type_name -> pokemon_api.PokemonId
+	5,  // 2: pokemon_api.PokemonDnf.Iv:type_name -> pokemon_api.RangeMinMax
+	5,  // 3: pokemon_api.PokemonDnf.AtkIv:type_name -> pokemon_api.RangeMinMax
+	5,  // 4: pokemon_api.PokemonDnf.DefIv:type_name -> pokemon_api.RangeMinMax
+	5,  // 5: pokemon_api.PokemonDnf.StaIv:type_name -> pokemon_api.RangeMinMax
+	5,  // 6: pokemon_api.PokemonDnf.Level:type_name -> pokemon_api.RangeMinMax
+	5,  // 7: pokemon_api.PokemonDnf.Cp:type_name -> pokemon_api.RangeMinMax
+	5,  // 8: pokemon_api.PokemonDnf.Gender:type_name -> pokemon_api.RangeMinMax
+	5,  // 9: pokemon_api.PokemonDnf.Size:type_name -> pokemon_api.RangeMinMax
+	5,  // 10: pokemon_api.PokemonDnf.PvpLittleRanking:type_name -> pokemon_api.RangeMinMax
+	5,  // 11: pokemon_api.PokemonDnf.PvpGreatRanking:type_name -> pokemon_api.RangeMinMax
+	5,  // 12: pokemon_api.PokemonDnf.PvpUltraRanking:type_name -> pokemon_api.RangeMinMax
 	0,  // 13: pokemon_api.PokemonScanResponse.status:type_name -> pokemon_api.PokemonScanResponse.Status
-	6,  // 14: pokemon_api.PokemonScanResponse.pokemon:type_name -> pokemon_api.PokemonDetails
-	1,  // 15: pokemon_api.Pokemon.Search:input_type -> pokemon_api.PokemonScanRequest
-	5,  // 16: pokemon_api.Pokemon.Search:output_type -> pokemon_api.PokemonScanResponse
-	16, // [16:17] is the sub-list for method output_type
-	15, // [15:16] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	7,  // 14: pokemon_api.PokemonScanResponse.pokemon:type_name -> pokemon_api.PokemonDetails
+	9,  // 15: pokemon_api.GymScanRequest.filters:type_name -> pokemon_api.GymDnf
+	5,  // 16: pokemon_api.GymDnf.available_slots:type_name -> pokemon_api.RangeMinMax
+	5,  // 17: pokemon_api.GymDnf.raid_level:type_name -> pokemon_api.RangeMinMax
+	4,  // 18: pokemon_api.GymDnf.raid_pokemon:type_name -> pokemon_api.PokemonId
+	5,  // 19: pokemon_api.GymDnf.power_up_level:type_name -> pokemon_api.RangeMinMax
+	1,  // 20: pokemon_api.GymScanResponse.status:type_name -> pokemon_api.GymScanResponse.Status
+	11, // 21: pokemon_api.GymScanResponse.gyms:type_name -> pokemon_api.GymDetails
+	2,  // 22: pokemon_api.Pokemon.Search:input_type -> pokemon_api.PokemonScanRequest
+	8,  // 23: pokemon_api.Gym.Search:input_type -> pokemon_api.GymScanRequest
+	6,  // 24: pokemon_api.Pokemon.Search:output_type -> pokemon_api.PokemonScanResponse
+	10, // 25: pokemon_api.Gym.Search:output_type -> pokemon_api.GymScanResponse
+	24, // [24:26] is the sub-list for method output_type
+	22, // [22:24] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_grpc_pokemon_api_proto_init() }
@@ -1055,7 +1781,7 @@ func file_grpc_pokemon_api_proto_init() {
 		return
 	}
 	if !protoimpl.UnsafeEnabled {
-		file_grpc_pokemon_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+		file_grpc_pokemon_api_proto_msgTypes[0].Exporter = func(v any, i int) any {
 			switch v := v.(*PokemonScanRequest); i {
 			case 0:
 				return &v.state
@@ -1067,7 +1793,7 @@ func file_grpc_pokemon_api_proto_init() {
 				return nil
 			}
 		}
-		file_grpc_pokemon_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+		file_grpc_pokemon_api_proto_msgTypes[1].Exporter = func(v any, i int) any {
 			switch v := v.(*PokemonDnf); i {
 			case 0:
 				return &v.state
@@ -1079,7 +1805,7 @@ func file_grpc_pokemon_api_proto_init() {
 				return nil
 			}
 		}
-		file_grpc_pokemon_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_grpc_pokemon_api_proto_msgTypes[2].Exporter = func(v any, i int) any {
 			switch v := v.(*PokemonId); i {
 			case 0:
 				return &v.state
@@ -1091,7 +1817,7 @@ func file_grpc_pokemon_api_proto_init() {
 				return nil
 			}
 		}
-		file_grpc_pokemon_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_grpc_pokemon_api_proto_msgTypes[3].Exporter = func(v any, i int) any {
 			switch v := v.(*RangeMinMax); i {
 			case 0:
 				return &v.state
@@ -1103,7 +1829,7 @@ func file_grpc_pokemon_api_proto_init() {
 				return nil
 			}
 		}
-		file_grpc_pokemon_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_grpc_pokemon_api_proto_msgTypes[4].Exporter = func(v any, i int) any {
 			switch v := v.(*PokemonScanResponse); i {
 			case 0:
 				return &v.state
@@ -1115,7 +1841,7 @@ func file_grpc_pokemon_api_proto_init() {
 				return nil
 			}
 		}
-		file_grpc_pokemon_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_grpc_pokemon_api_proto_msgTypes[5].Exporter = func(v any, i int) any {
 			switch v := v.(*PokemonDetails); i {
 			case 0:
 				return &v.state
@@ -1127,20 +1853,70 @@ func file_grpc_pokemon_api_proto_init() {
 				return nil
 			}
 		}
+		file_grpc_pokemon_api_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*GymScanRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_pokemon_api_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*GymDnf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_pokemon_api_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*GymScanResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpc_pokemon_api_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*GymDetails); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	file_grpc_pokemon_api_proto_msgTypes[1].OneofWrappers = []interface{}{}
-	file_grpc_pokemon_api_proto_msgTypes[2].OneofWrappers = []interface{}{}
-	file_grpc_pokemon_api_proto_msgTypes[3].OneofWrappers = []interface{}{}
-	file_grpc_pokemon_api_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_grpc_pokemon_api_proto_msgTypes[1].OneofWrappers = []any{}
+	file_grpc_pokemon_api_proto_msgTypes[2].OneofWrappers = []any{}
+	file_grpc_pokemon_api_proto_msgTypes[3].OneofWrappers = []any{}
+	file_grpc_pokemon_api_proto_msgTypes[5].OneofWrappers = []any{}
+	file_grpc_pokemon_api_proto_msgTypes[7].OneofWrappers = []any{}
+	file_grpc_pokemon_api_proto_msgTypes[9].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_grpc_pokemon_api_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   6,
+			NumEnums:      2,
+			NumMessages:   10,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_grpc_pokemon_api_proto_goTypes,
 		DependencyIndexes: file_grpc_pokemon_api_proto_depIdxs,
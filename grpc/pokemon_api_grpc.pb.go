@@ -103,3 +103,89 @@ var Pokemon_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc/pokemon_api.proto",
 }
+
+// GymClient is the client API for Gym service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GymClient interface {
+	Search(ctx context.Context, in *GymScanRequest, opts ...grpc.CallOption) (*GymScanResponse, error)
+}
+
+type gymClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGymClient(cc grpc.ClientConnInterface) GymClient {
+	return &gymClient{cc}
+}
+
+func (c *gymClient) Search(ctx context.Context, in *GymScanRequest, opts ...grpc.CallOption) (*GymScanResponse, error) {
+	out := new(GymScanResponse)
+	err := c.cc.Invoke(ctx, "/pokemon_api.Gym/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GymServer is the server API for Gym service.
+// All implementations must embed UnimplementedGymServer
+// for forward compatibility
+type GymServer interface {
+	Search(context.Context, *GymScanRequest) (*GymScanResponse, error)
+	mustEmbedUnimplementedGymServer()
+}
+
+// UnimplementedGymServer must be embedded to have forward compatible implementations.
+type UnimplementedGymServer struct {
+}
+
+func (UnimplementedGymServer) Search(context.Context, *GymScanRequest) (*GymScanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedGymServer) mustEmbedUnimplementedGymServer() {}
+
+// UnsafeGymServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GymServer will
+// result in compilation errors.
+type UnsafeGymServer interface {
+	mustEmbedUnimplementedGymServer()
+}
+
+func RegisterGymServer(s grpc.ServiceRegistrar, srv GymServer) {
+	s.RegisterService(&Gym_ServiceDesc, srv)
+}
+
+func _Gym_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GymScanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GymServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pokemon_api.Gym/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GymServer).Search(ctx, req.(*GymScanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Gym_ServiceDesc is the grpc.ServiceDesc for Gym service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Gym_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pokemon_api.Gym",
+	HandlerType: (*GymServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _Gym_Search_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "grpc/pokemon_api.proto",
+}
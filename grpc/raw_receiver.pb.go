@@ -249,6 +249,69 @@ func (x *RawProtoResponse) GetMessage() string {
 	return ""
 }
 
+type RawProtoAck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sequence uint64 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Accepted bool   `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Message  string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *RawProtoAck) Reset() {
+	*x = RawProtoAck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpc_raw_receiver_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RawProtoAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawProtoAck) ProtoMessage() {}
+
+func (x *RawProtoAck) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_raw_receiver_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawProtoAck.ProtoReflect.Descriptor instead.
+func (*RawProtoAck) Descriptor() ([]byte, []int) {
+	return file_grpc_raw_receiver_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RawProtoAck) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *RawProtoAck) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *RawProtoAck) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 var File_grpc_raw_receiver_proto protoreflect.FileDescriptor
 
 var file_grpc_raw_receiver_proto_rawDesc = []byte{
@@ -291,15 +354,27 @@ var file_grpc_raw_receiver_proto_rawDesc = []byte{
 	0x61, 0x76, 0x65, 0x5f, 0x61, 0x72, 0x22, 0x2c, 0x0a, 0x10, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f,
 	0x74, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x32, 0x5d, 0x0a, 0x08, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x51, 0x0a, 0x0e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x1d, 0x2e, 0x72, 0x61, 0x77, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
-	0x72, 0x2e, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1e, 0x2e, 0x72, 0x61, 0x77, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72,
-	0x2e, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x00, 0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x75, 0x6e, 0x6f, 0x77, 0x6e, 0x68, 0x61, 0x73, 0x68, 0x2f, 0x67, 0x6f, 0x6c, 0x62,
-	0x61, 0x74, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x61, 0x67, 0x65, 0x22, 0x5f, 0x0a, 0x0b, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f,
+	0x41, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12,
+	0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0xaf, 0x01, 0x0a, 0x08, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x51, 0x0a, 0x0e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x52, 0x61, 0x77, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1d, 0x2e, 0x72, 0x61, 0x77, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x72, 0x2e, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x72, 0x61, 0x77, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x72, 0x2e, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x0e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52,
+	0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1d, 0x2e, 0x72, 0x61, 0x77, 0x5f, 0x72, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x2e, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x72, 0x61, 0x77, 0x5f, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x72, 0x2e, 0x52, 0x61, 0x77, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x41, 0x63,
+	0x6b, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x75, 0x6e, 0x6f, 0x77, 0x6e, 0x68, 0x61, 0x73, 0x68, 0x2f,
+	0x67, 0x6f, 0x6c, 0x62, 0x61, 0x74, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -314,18 +389,21 @@ func file_grpc_raw_receiver_proto_rawDescGZIP() []byte {
 	return file_grpc_raw_receiver_proto_rawDescData
 }
 
-var file_grpc_raw_receiver_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_grpc_raw_receiver_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_grpc_raw_receiver_proto_goTypes = []interface{}{
 	(*RawProtoRequest)(nil),  // 0: raw_receiver.RawProtoRequest
 	(*Content)(nil),          // 1: raw_receiver.Content
 	(*RawProtoResponse)(nil), // 2: raw_receiver.RawProtoResponse
+	(*RawProtoAck)(nil),      // 3: raw_receiver.RawProtoAck
 }
 var file_grpc_raw_receiver_proto_depIdxs = []int32{
 	1, // 0: raw_receiver.RawProtoRequest.contents:type_name -> raw_receiver.Content
 	0, // 1: raw_receiver.RawProto.SubmitRawProto:input_type -> raw_receiver.RawProtoRequest
-	2, // 2: raw_receiver.RawProto.SubmitRawProto:output_type -> raw_receiver.RawProtoResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
+	0, // 2: raw_receiver.RawProto.StreamRawProto:input_type -> raw_receiver.RawProtoRequest
+	2, // 3: raw_receiver.RawProto.SubmitRawProto:output_type -> raw_receiver.RawProtoResponse
+	3, // 4: raw_receiver.RawProto.StreamRawProto:output_type -> raw_receiver.RawProtoAck
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
 	1, // [1:1] is the sub-list for extension type_name
 	1, // [1:1] is the sub-list for extension extendee
 	0, // [0:1] is the sub-list for field type_name
@@ -373,6 +451,18 @@ func file_grpc_raw_receiver_proto_init() {
 				return nil
 			}
 		}
+		file_grpc_raw_receiver_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RawProtoAck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_grpc_raw_receiver_proto_msgTypes[0].OneofWrappers = []interface{}{}
 	file_grpc_raw_receiver_proto_msgTypes[1].OneofWrappers = []interface{}{}
@@ -382,7 +472,7 @@ func file_grpc_raw_receiver_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_grpc_raw_receiver_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,6 +23,7 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RawProtoClient interface {
 	SubmitRawProto(ctx context.Context, in *RawProtoRequest, opts ...grpc.CallOption) (*RawProtoResponse, error)
+	StreamRawProto(ctx context.Context, opts ...grpc.CallOption) (RawProto_StreamRawProtoClient, error)
 }
 
 type rawProtoClient struct {
@@ -42,11 +43,43 @@ func (c *rawProtoClient) SubmitRawProto(ctx context.Context, in *RawProtoRequest
 	return out, nil
 }
 
+func (c *rawProtoClient) StreamRawProto(ctx context.Context, opts ...grpc.CallOption) (RawProto_StreamRawProtoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RawProto_ServiceDesc.Streams[0], "/raw_receiver.RawProto/StreamRawProto", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rawProtoStreamRawProtoClient{stream}
+	return x, nil
+}
+
+type RawProto_StreamRawProtoClient interface {
+	Send(*RawProtoRequest) error
+	Recv() (*RawProtoAck, error)
+	grpc.ClientStream
+}
+
+type rawProtoStreamRawProtoClient struct {
+	grpc.ClientStream
+}
+
+func (x *rawProtoStreamRawProtoClient) Send(m *RawProtoRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *rawProtoStreamRawProtoClient) Recv() (*RawProtoAck, error) {
+	m := new(RawProtoAck)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RawProtoServer is the server API for RawProto service.
 // All implementations must embed UnimplementedRawProtoServer
 // for forward compatibility
 type RawProtoServer interface {
 	SubmitRawProto(context.Context, *RawProtoRequest) (*RawProtoResponse, error)
+	StreamRawProto(RawProto_StreamRawProtoServer) error
 	mustEmbedUnimplementedRawProtoServer()
 }
 
@@ -57,6 +90,9 @@ type UnimplementedRawProtoServer struct {
 func (UnimplementedRawProtoServer) SubmitRawProto(context.Context, *RawProtoRequest) (*RawProtoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SubmitRawProto not implemented")
 }
+func (UnimplementedRawProtoServer) StreamRawProto(RawProto_StreamRawProtoServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRawProto not implemented")
+}
 func (UnimplementedRawProtoServer) mustEmbedUnimplementedRawProtoServer() {}
 
 // UnsafeRawProtoServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +124,32 @@ func _RawProto_SubmitRawProto_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RawProto_StreamRawProto_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RawProtoServer).StreamRawProto(&rawProtoStreamRawProtoServer{stream})
+}
+
+type RawProto_StreamRawProtoServer interface {
+	Send(*RawProtoAck) error
+	Recv() (*RawProtoRequest, error)
+	grpc.ServerStream
+}
+
+type rawProtoStreamRawProtoServer struct {
+	grpc.ServerStream
+}
+
+func (x *rawProtoStreamRawProtoServer) Send(m *RawProtoAck) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *rawProtoStreamRawProtoServer) Recv() (*RawProtoRequest, error) {
+	m := new(RawProtoRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RawProto_ServiceDesc is the grpc.ServiceDesc for RawProto service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -100,6 +162,13 @@ var RawProto_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _RawProto_SubmitRawProto_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRawProto",
+			Handler:       _RawProto_StreamRawProto_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "grpc/raw_receiver.proto",
 }
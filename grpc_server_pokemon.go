@@ -6,8 +6,10 @@ import (
 	"golbat/config"
 	"golbat/decoder"
 	pb "golbat/grpc"
+	"google.golang.org/grpc/codes"
 	_ "google.golang.org/grpc/encoding/gzip" // Install the gzip compressor
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // server is used to implement helloworld.GreeterServer.
@@ -32,3 +34,29 @@ func (s *grpcPokemonServer) Search(ctx context.Context, in *pb.PokemonScanReques
 		Pokemon: decoder.GrpcGetPokemonInArea2(in),
 	}, nil
 }
+
+type grpcGymServer struct {
+	pb.UnimplementedGymServer
+}
+
+func (s *grpcGymServer) Search(ctx context.Context, in *pb.GymScanRequest) (*pb.GymScanResponse, error) {
+	// Check for authorisation
+	if config.Config.ApiSecret != "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		if auth := md.Get("authorization"); len(auth) == 0 || auth[0] != config.Config.ApiSecret {
+			return &pb.GymScanResponse{}, nil
+		}
+	}
+
+	if !config.Config.TestFortInMemory {
+		return nil, status.Error(codes.FailedPrecondition, "test_fort_in_memory is not enabled")
+	}
+
+	log.Infof("Received request %+v", in)
+
+	return &pb.GymScanResponse{
+		Status: pb.GymScanResponse_SUCCESS,
+		Gyms:   decoder.GrpcGetGymInArea(ctx, dbDetails, in),
+	}, nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"golbat/config"
 	pb "golbat/grpc"
+	"google.golang.org/grpc/codes"
 	_ "google.golang.org/grpc/encoding/gzip" // Install the gzip compressor
 	"google.golang.org/grpc/metadata"
-	"time"
+	"google.golang.org/grpc/status"
+	"io"
 )
 
 // server is used to implement helloworld.GreeterServer.
@@ -16,14 +18,85 @@ type grpcRawServer struct {
 
 func (s *grpcRawServer) SubmitRawProto(ctx context.Context, in *pb.RawProtoRequest) (*pb.RawProtoResponse, error) {
 	// Check for authorisation
-	if config.Config.RawBearer != "" {
-		md, _ := metadata.FromIncomingContext(ctx)
+	credentialName, credential, authorised := grpcRawCredential(ctx)
+	if !authorised {
+		return &pb.RawProtoResponse{Message: "Incorrect authorisation received"}, nil
+	}
+
+	if err := submitRawProtoRequest("grpc", credentialName, credential, grpcUserAgent(ctx), in); err != nil {
+		switch err {
+		case errOutsideCredentialScope:
+			return nil, status.Error(codes.PermissionDenied, "Outside permitted scope")
+		case errDecodeQueueClosed:
+			return nil, status.Error(codes.Unavailable, "Shutting down")
+		}
+		return nil, status.Error(codes.ResourceExhausted, "Decode queue full")
+	}
+
+	return &pb.RawProtoResponse{Message: "Processed"}, nil
+}
+
+// StreamRawProto accepts a continuous stream of raw requests from a single connection,
+// acknowledging each one in the order received
+func (s *grpcRawServer) StreamRawProto(stream pb.RawProto_StreamRawProtoServer) error {
+	// Check for authorisation once, for the whole stream
+	credentialName, credential, authorised := grpcRawCredential(stream.Context())
+	if !authorised {
+		return status.Error(codes.Unauthenticated, "Incorrect authorisation received")
+	}
+	userAgent := grpcUserAgent(stream.Context())
+
+	for sequence := uint64(1); ; sequence++ {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ack := &pb.RawProtoAck{Sequence: sequence, Accepted: true, Message: "Processed"}
+		if err := submitRawProtoRequest("grpc_stream", credentialName, credential, userAgent, in); err != nil {
+			ack.Accepted = false
+			switch err {
+			case errOutsideCredentialScope:
+				ack.Message = "Outside permitted scope"
+			case errDecodeQueueClosed:
+				ack.Message = "Shutting down"
+			default:
+				ack.Message = "Decode queue full"
+			}
+		}
 
-		if auth := md.Get("authorization"); len(auth) == 0 || auth[0] != config.Config.RawBearer {
-			return &pb.RawProtoResponse{Message: "Incorrect authorisation received"}, nil
+		if err := stream.Send(ack); err != nil {
+			return err
 		}
 	}
+}
+
+// grpcRawCredential finds the raw credential for the bearer token sent in the request metadata
+func grpcRawCredential(ctx context.Context) (string, *config.RawCredential, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	token := ""
+	if auth := md.Get("authorization"); len(auth) > 0 {
+		token = auth[0]
+	}
+	return authoriseRawToken(token)
+}
+
+// grpcUserAgent returns the user agent sent by the grpc client
+func grpcUserAgent(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+		return userAgent[0]
+	}
+	return ""
+}
 
+// submitRawProtoRequest normalises a grpc raw request and queues it for decoding,
+// tracking the device location once accepted
+func submitRawProtoRequest(source string, credentialName string, credential *config.RawCredential, userAgent string, in *pb.RawProtoRequest) error {
 	uuid := in.DeviceId
 	account := in.Username
 	level := int(in.TrainerLevel)
@@ -33,6 +106,10 @@ func (s *grpcRawServer) SubmitRawProto(ctx context.Context, in *pb.RawProtoReque
 	}
 
 	latTarget, lonTarget := float64(in.LatTarget), float64(in.LonTarget)
+	scanContext, err := scopeRawRequest(credentialName, credential, scanContext, latTarget, lonTarget)
+	if err != nil {
+		return err
+	}
 	globalHaveAr := in.HaveAr
 	var protoData []ProtoData
 
@@ -48,6 +125,8 @@ func (s *grpcRawServer) SubmitRawProto(ctx context.Context, in *pb.RawProtoReque
 			Data:        v.ResponsePayload,
 			Request:     v.RequestPayload,
 			Uuid:        uuid,
+			UserAgent:   userAgent,
+			Timestamp:   normaliseTimestampMs(in.Timestamp),
 			HaveAr: func() *bool {
 				if v.HaveAr != nil {
 					return v.HaveAr
@@ -59,24 +138,14 @@ func (s *grpcRawServer) SubmitRawProto(ctx context.Context, in *pb.RawProtoReque
 		protoData = append(protoData, inboundRawData)
 	}
 
-	// Process each proto in a packet in sequence, but in a go-routine
-	go func() {
-		timeout := 5 * time.Second
-		if config.Config.Tuning.ExtendedTimeout {
-			timeout = 30 * time.Second
-		}
-
-		for _, entry := range protoData {
-			// provide independent cancellation contexts for each proto decode
-			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			decode(ctx, entry.Method, &entry)
-			cancel()
-		}
-	}()
+	// Protos in a packet are processed in sequence by a decode worker
+	if err := rawDecodeQueue.Enqueue(source, protoData); err != nil {
+		return err
+	}
 
 	if latTarget != 0 && lonTarget != 0 && uuid != "" {
-		UpdateDeviceLocation(uuid, latTarget, lonTarget, scanContext)
+		UpdateDeviceLocation(uuid, latTarget, lonTarget, scanContext, account)
 	}
 
-	return &pb.RawProtoResponse{Message: "Processed"}, nil
+	return nil
 }
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +39,11 @@ var dbDetails db2.DbDetails
 var statsCollector stats_collector.StatsCollector
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		runReplay(os.Args[2:])
+		return
+	}
+
 	var wg sync.WaitGroup
 	ctx, cancelFn := context.WithCancel(context.Background())
 	defer cancelFn()
@@ -80,54 +87,11 @@ func main() {
 	}
 	decoder.SetWebhooksSender(webhooksSender)
 
-	// Capture connection properties.
-	mysqlConfig := mysql.Config{
-		User:                 cfg.Database.User,     //"root",     //os.Getenv("DBUSER"),
-		Passwd:               cfg.Database.Password, //"transmit", //os.Getenv("DBPASS"),
-		Net:                  "tcp",
-		Addr:                 cfg.Database.Addr,
-		DBName:               cfg.Database.Db,
-		AllowNativePasswords: true,
-	}
-
-	dbConnectionString := mysqlConfig.FormatDSN()
-	driver := "mysql"
-
-	log.Infof("Starting migration")
-
-	m, err := migrate.New(
-		"file://sql",
-		driver+"://"+dbConnectionString+"&multiStatements=true")
-	if err != nil {
-		log.Fatal(err)
-		return
-	}
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
-		log.Fatal(err)
-		return
-	}
-
-	log.Infof("Opening database for processing, max pool = %d", cfg.Database.MaxPool)
-
-	// Get a database handle.
-
-	db, err = sqlx.Open(driver, dbConnectionString)
+	db, err = openDatabase()
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
-
-	db.SetConnMaxLifetime(time.Minute * 3) // Recommended by go mysql driver
-	db.SetMaxOpenConns(cfg.Database.MaxPool)
-	db.SetMaxIdleConns(10)
-	db.SetConnMaxIdleTime(time.Minute)
-
-	pingErr := db.Ping()
-	if pingErr != nil {
-		log.Fatal(pingErr)
-		return
-	}
 	log.Infoln("Connected to database")
 
 	decoder.SetKojiUrl(cfg.Koji.Url, cfg.Koji.BearerToken)
@@ -192,7 +156,11 @@ func main() {
 
 	decoder.InitialiseOhbem()
 	decoder.LoadStatsGeofences()
-	InitDeviceCache()
+	InitDeviceCache(webhooksSender)
+	StartDeviceMonitor(ctx, &wg)
+	InitProtoDedup()
+	StartRawRecorder()
+	StartDecodeQueue(ctx, &wg)
 
 	wg.Add(1)
 	go func() {
@@ -234,6 +202,7 @@ func main() {
 		go decoder.LoadAllPokestops(dbDetails)
 		go decoder.LoadAllGyms(dbDetails)
 	}
+	go decoder.LoadEncounteredSpawnpoints(dbDetails)
 
 	// Start the GRPC receiver
 
@@ -247,6 +216,7 @@ func main() {
 			s := grpc.NewServer()
 			pb.RegisterRawProtoServer(s, &grpcRawServer{})
 			pb.RegisterPokemonServer(s, &grpcPokemonServer{})
+			pb.RegisterGymServer(s, &grpcGymServer{})
 			log.Printf("grpc server listening at %v", lis.Addr())
 			if err := s.Serve(lis); err != nil {
 				log.Fatalf("failed to serve: %v", err)
@@ -263,6 +233,18 @@ func main() {
 	apiGroup.POST("/quest-status", GetQuestStatus)
 	apiGroup.POST("/pokestop-positions", GetPokestopPositions)
 	apiGroup.GET("/pokestop/id/:fort_id", GetPokestop)
+	apiGroup.POST("/pokestop/scan", PokestopScan)
+	apiGroup.GET("/gym/id/:gym_id", GetGym)
+	apiGroup.POST("/gym/scan", GymScan)
+	apiGroup.POST("/raids/scan", RaidScan)
+	apiGroup.POST("/incidents/scan", IncidentScan)
+	apiGroup.POST("/stations/scan", StationScan)
+	apiGroup.POST("/routes/scan", RouteScan)
+	apiGroup.POST("/weather/scan", WeatherScan)
+	apiGroup.GET("/weather/history", GetWeatherHistory)
+	apiGroup.POST("/spawnpoints/scan", SpawnpointScan)
+	apiGroup.POST("/spawnpoints/upcoming", UpcomingSpawns)
+	apiGroup.POST("/queue/encounter", EncounterQueue)
 	apiGroup.POST("/reload-geojson", ReloadGeojson)
 	apiGroup.GET("/reload-geojson", ReloadGeojson)
 
@@ -273,6 +255,15 @@ func main() {
 	apiGroup.POST("/pokemon/search", PokemonSearch)
 
 	apiGroup.GET("/devices/all", GetDevices)
+	apiGroup.POST("/devices/id/:device_id/areas", SetDeviceAreas)
+	apiGroup.GET("/accounts", GetAccounts)
+	apiGroup.GET("/accounts/:username", GetAccount)
+
+	apiGroup.GET("/debug/dead-letters", GetDeadLetters)
+	apiGroup.GET("/debug/dead-letters/download", DownloadDeadLetters)
+	apiGroup.POST("/debug/dead-letters/retry", RetryDeadLetters)
+	apiGroup.GET("/debug/dead-letters/id/:id", GetDeadLetter)
+	apiGroup.POST("/debug/dead-letters/id/:id/retry", RetryDeadLetter)
 
 	debugGroup := r.Group("/debug")
 
@@ -343,67 +334,209 @@ func main() {
 	log.Info("http server is shutdown, waiting for other go routines to exit...")
 	wg.Wait()
 
+	protoRecorder.Close()
+
 	log.Info("go routines have exited, flushing webhooks now...")
 	webhooksSender.Flush()
 
 	log.Info("Golbat exiting!")
 }
 
-func decode(ctx context.Context, method int, protoData *ProtoData) {
-	getMethodName := func(method int, trimString bool) string {
-		if val, ok := pogo.Method_name[int32(method)]; ok {
-			if trimString && strings.HasPrefix(val, "METHOD_") {
-				return strings.TrimPrefix(val, "METHOD_")
-			}
-			return val
+// openDatabase applies any outstanding migrations and opens a handle to the database
+// described by config.Config
+func openDatabase() (*sqlx.DB, error) {
+	cfg := config.Config
+
+	// Capture connection properties.
+	mysqlConfig := mysql.Config{
+		User:                 cfg.Database.User,     //"root",     //os.Getenv("DBUSER"),
+		Passwd:               cfg.Database.Password, //"transmit", //os.Getenv("DBPASS"),
+		Net:                  "tcp",
+		Addr:                 cfg.Database.Addr,
+		DBName:               cfg.Database.Db,
+		AllowNativePasswords: true,
+	}
+
+	dbConnectionString := mysqlConfig.FormatDSN()
+	driver := "mysql"
+
+	log.Infof("Starting migration")
+
+	m, err := migrate.New(
+		"file://sql",
+		driver+"://"+dbConnectionString+"&multiStatements=true")
+	if err != nil {
+		return nil, err
+	}
+	err = m.Up()
+	if err != nil && err != migrate.ErrNoChange {
+		return nil, err
+	}
+
+	log.Infof("Opening database for processing, max pool = %d", cfg.Database.MaxPool)
+
+	// Get a database handle.
+
+	database, err := sqlx.Open(driver, dbConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	database.SetConnMaxLifetime(time.Minute * 3) // Recommended by go mysql driver
+	database.SetMaxOpenConns(cfg.Database.MaxPool)
+	database.SetMaxIdleConns(10)
+	database.SetConnMaxIdleTime(time.Minute)
+
+	if pingErr := database.Ping(); pingErr != nil {
+		return nil, pingErr
+	}
+	return database, nil
+}
+
+// DecodeResult is the outcome of decoding a single proto
+type DecodeResult struct {
+	Method string `json:"method"`
+	Status string `json:"status"` // processed, ignored or error
+	Result string `json:"result"`
+
+	parseFailed bool // the proto could not be parsed, so is worth keeping as a dead letter
+}
+
+const (
+	DecodeStatusProcessed = "processed"
+	DecodeStatusIgnored   = "ignored"
+	DecodeStatusError     = "error"
+)
+
+// errDecodeIgnored is returned by a decoder when the proto was read but holds nothing to
+// save, such as a non-success response. It is reported as ignored rather than an error.
+var errDecodeIgnored = errors.New("decode ignored")
+var errDecodeParse = errors.New("parse failure")
+
+// parseFailure marks an error from unmarshalling a proto, so the proto is kept as a dead
+// letter. A nil error is returned unchanged.
+func parseFailure(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", errDecodeParse, err)
+}
+
+func getMethodName(method int, trimString bool) string {
+	if val, ok := pogo.Method_name[int32(method)]; ok {
+		if trimString && strings.HasPrefix(val, "METHOD_") {
+			return strings.TrimPrefix(val, "METHOD_")
 		}
-		return fmt.Sprintf("#%d", method)
+		return val
 	}
+	return fmt.Sprintf("#%d", method)
+}
 
-	if method != int(pogo.InternalPlatformClientAction_INTERNAL_PROXY_SOCIAL_ACTION) && protoData.Level < 30 {
-		statsCollector.IncDecodeMethods("error", "low_level", getMethodName(method, true))
-		log.Debugf("Insufficient Level %d Did not process hook type %s", protoData.Level, pogo.Method(method))
-		return
+// decode checks a proto against the scan rules and duplicate window before decoding it.
+// checkMaxAge is set for protos just received, rather than replayed.
+func decode(ctx context.Context, method int, protoData *ProtoData, checkMaxAge bool) DecodeResult {
+	decodeResult := DecodeResult{Method: getMethodName(method, true)}
+
+	// Account and device activity is tracked for every proto received, including those the
+	// scan rules refuse, as low level and flagged accounts are the ones worth watching
+	decoder.RecordAccountProto(protoData.Account, protoData.Uuid, protoData.Level, protoData.Lat, protoData.Lon,
+		decodeResult.Method)
+	RecordDeviceProto(protoData.Uuid, protoData.Account, decodeResult.Method)
+
+	// The scan rule for the context and location decides which methods are accepted, and
+	// from which trainer levels
+	scanParameters := getScanParameters(protoData)
+	if reason := scanParameters.CheckMethod(method, decodeResult.Method, protoData.Level); reason != "" {
+		statsCollector.IncDecodeMethods("error", reason, decodeResult.Method)
+		decodeResult.Status = DecodeStatusIgnored
+		if reason == "low_level" {
+			log.Debugf("Insufficient Level %d Did not process hook type %s", protoData.Level, pogo.Method(method))
+			decodeResult.Result = fmt.Sprintf("Insufficient level %d", protoData.Level)
+		} else {
+			log.Debugf("Scan rules do not permit hook type %s (%s)", pogo.Method(method), reason)
+			decodeResult.Result = fmt.Sprintf("Not permitted by scan rules (%s)", reason)
+		}
+		return decodeResult
+	}
+
+	// Identical data already claimed by another device is dropped before any record
+	// lookups. GMOs are instead filtered per cell in decodeGMO
+	claimKey := ""
+	if pogo.Method(method) != pogo.Method_METHOD_GET_MAP_OBJECTS {
+		var claimed bool
+		if claimKey, claimed = claimProto(method, protoData); !claimed {
+			decodeResult.Status = DecodeStatusIgnored
+			decodeResult.Result = "Duplicate"
+			return decodeResult
+		}
+	}
+
+	decodeResult = decodeAsAt(ctx, method, protoData, scanParameters, checkMaxAge)
+	if decodeResult.Status != DecodeStatusProcessed {
+		// a proto which was not decoded can still be decoded from another device
+		releaseProtoClaim(claimKey)
+	}
+	return decodeResult
+}
+
+// decodeAsAt decodes a proto which has passed the scan rule and duplicate checks. Data is
+// saved as at the time the sender saw it, so a late upload from a device cannot overwrite
+// fresher state. From here Timestamp holds that as-at time. Protos older than the max age
+// are only dropped when checkMaxAge is set; a retry or replay is expected to be old.
+func decodeAsAt(ctx context.Context, method int, protoData *ProtoData, scanParameters decoder.ScanParameters, checkMaxAge bool) DecodeResult {
+	decodeResult := DecodeResult{Method: getMethodName(method, true)}
+
+	now := time.Now()
+	asAtMs := asAtTimeMs(protoData.Timestamp, now.UnixMilli(), now)
+	if checkMaxAge && isOlderThanMaxAge(asAtMs, now.UnixMilli()) {
+		statsCollector.IncStaleUpdates("proto")
+		decodeResult.Status = DecodeStatusIgnored
+		decodeResult.Result = fmt.Sprintf("Stale, sent %ds ago", (now.UnixMilli()-asAtMs)/1000)
+		return decodeResult
 	}
+	protoData.Timestamp = asAtMs
 
 	processed := false
 	ignore := false
 	start := time.Now()
 	result := ""
+	var err error
 
 	switch pogo.Method(method) {
 	case pogo.Method_METHOD_START_INCIDENT:
-		result = decodeStartIncident(ctx, protoData.Data)
+		result, err = decodeStartIncident(ctx, protoData.Data)
 		processed = true
 	case pogo.Method_METHOD_INVASION_OPEN_COMBAT_SESSION:
 		if protoData.Request != nil {
-			result = decodeOpenInvasion(ctx, protoData.Request, protoData.Data)
+			result, err = decodeOpenInvasion(ctx, protoData.Request, protoData.Data)
 			processed = true
 		}
 		break
 	case pogo.Method_METHOD_FORT_DETAILS:
-		result = decodeFortDetails(ctx, protoData.Data)
+		result, err = decodeFortDetails(ctx, protoData.Data, protoData.Timestamp)
 		processed = true
 	case pogo.Method_METHOD_GET_MAP_OBJECTS:
-		result = decodeGMO(ctx, protoData, getScanParameters(protoData))
+		result, err = decodeGMO(ctx, protoData, scanParameters)
 		processed = true
 	case pogo.Method_METHOD_GYM_GET_INFO:
-		result = decodeGetGymInfo(ctx, protoData.Data)
+		result, err = decodeGetGymInfo(ctx, protoData.Data, protoData.Timestamp)
 		processed = true
 	case pogo.Method_METHOD_ENCOUNTER:
-		if getScanParameters(protoData).ProcessPokemon {
-			result = decodeEncounter(ctx, protoData.Data, protoData.Account)
+		if scanParameters.ProcessPokemon {
+			result, err = decodeEncounter(ctx, protoData.Data, protoData.Account, protoData.Timestamp)
+		} else {
+			result, err = "Pokemon not processed by scan rules", errDecodeIgnored
 		}
 		processed = true
 	case pogo.Method_METHOD_DISK_ENCOUNTER:
-		result = decodeDiskEncounter(ctx, protoData.Data, protoData.Account)
+		result, err = decodeDiskEncounter(ctx, protoData.Data, protoData.Account, protoData.Timestamp)
 		processed = true
 	case pogo.Method_METHOD_FORT_SEARCH:
-		result = decodeQuest(ctx, protoData.Data, protoData.HaveAr)
+		result, err = decodeQuest(ctx, protoData.Data, protoData.HaveAr, protoData.Timestamp)
 		processed = true
 	case pogo.Method_METHOD_GET_PLAYER:
-		ignore = true
-		break
+		result, err = decodeGetPlayer(protoData.Account, protoData.Data)
+		processed = true
 	case pogo.Method_METHOD_GET_HOLOHOLO_INVENTORY:
 		ignore = true
 		break
@@ -412,31 +545,31 @@ func decode(ctx context.Context, method int, protoData *ProtoData) {
 		break
 	case pogo.Method(pogo.InternalPlatformClientAction_INTERNAL_PROXY_SOCIAL_ACTION):
 		if protoData.Request != nil {
-			result = decodeSocialActionWithRequest(protoData.Request, protoData.Data)
+			result, err = decodeSocialActionWithRequest(protoData.Request, protoData.Data)
 			processed = true
 		}
 		break
 	case pogo.Method_METHOD_GET_MAP_FORTS:
-		result = decodeGetMapForts(ctx, protoData.Data)
+		result, err = decodeGetMapForts(ctx, protoData.Data, protoData.Timestamp)
 		processed = true
 	case pogo.Method_METHOD_GET_ROUTES:
-		result = decodeGetRoutes(protoData.Data)
+		result, err = decodeGetRoutes(protoData.Data)
 		processed = true
 	case pogo.Method_METHOD_GET_CONTEST_DATA:
 		// Request helps, but can be decoded without it
-		result = decodeGetContestData(ctx, protoData.Request, protoData.Data)
+		result, err = decodeGetContestData(ctx, protoData.Request, protoData.Data, protoData.Timestamp)
 		processed = true
 		break
 	case pogo.Method_METHOD_GET_POKEMON_SIZE_CONTEST_ENTRY:
 		// Request is essential to decode this
 		if protoData.Request != nil {
-			result = decodeGetPokemonSizeContestEntry(ctx, protoData.Request, protoData.Data)
+			result, err = decodeGetPokemonSizeContestEntry(ctx, protoData.Request, protoData.Data, protoData.Timestamp)
 			processed = true
 		}
 		break
 	case pogo.Method_METHOD_GET_STATION_DETAILS:
 		// Request is essential to decode this
-		result = decodeGetStationDetails(ctx, protoData.Request, protoData.Data)
+		result, err = decodeGetStationDetails(ctx, protoData.Request, protoData.Data, protoData.Timestamp)
 		processed = true
 
 	default:
@@ -452,44 +585,61 @@ func decode(ctx context.Context, method int, protoData *ProtoData) {
 			statsCollector.IncDecodeMethods("unprocessed", "", getMethodName(method, true))
 		}
 	}
+
+	switch {
+	case errors.Is(err, errDecodeIgnored):
+		decodeResult.Status = DecodeStatusIgnored
+		decodeResult.Result = result
+	case err != nil:
+		decodeResult.Status = DecodeStatusError
+		decodeResult.Result = result
+		decodeResult.parseFailed = errors.Is(err, errDecodeParse)
+	case processed:
+		decodeResult.Status = DecodeStatusProcessed
+		decodeResult.Result = result
+	default:
+		decodeResult.Status = DecodeStatusIgnored
+		decodeResult.Result = "Did not process"
+	}
+	return decodeResult
 }
 
 func getScanParameters(protoData *ProtoData) decoder.ScanParameters {
 	return decoder.FindScanConfiguration(protoData.ScanContext, protoData.Lat, protoData.Lon)
 }
 
-func decodeQuest(ctx context.Context, sDec []byte, haveAr *bool) string {
+func decodeQuest(ctx context.Context, sDec []byte, haveAr *bool, timestampMs int64) (string, error) {
 	if haveAr == nil {
 		statsCollector.IncDecodeQuest("error", "missing_ar_info")
 		log.Infoln("Cannot determine AR quest - ignoring")
 		// We should either assume AR quest, or trace inventory like RDM probably
-		return "No AR quest info"
+		return "No AR quest info", errDecodeIgnored
 	}
 	decodedQuest := &pogo.FortSearchOutProto{}
 	if err := proto.Unmarshal(sDec, decodedQuest); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeQuest("error", "parse")
-		return "Parse failure"
+		return "Parse failure", parseFailure(err)
 	}
 
 	if decodedQuest.Result != pogo.FortSearchOutProto_SUCCESS {
 		statsCollector.IncDecodeQuest("error", "non_success")
 		res := fmt.Sprintf(`GymGetInfoOutProto: Ignored non-success value %d:%s`, decodedQuest.Result,
 			pogo.FortSearchOutProto_Result_name[int32(decodedQuest.Result)])
-		return res
+		return res, errDecodeIgnored
 	}
 
-	return decoder.UpdatePokestopWithQuest(ctx, dbDetails, decodedQuest, *haveAr)
+	return decoder.UpdatePokestopWithQuest(ctx, dbDetails, decodedQuest, *haveAr, timestampMs), nil
 
 }
 
-func decodeSocialActionWithRequest(request []byte, payload []byte) string {
+func decodeSocialActionWithRequest(request []byte, payload []byte) (string, error) {
 	var proxyRequestProto pogo.ProxyRequestProto
 
 	if err := proto.Unmarshal(request, &proxyRequestProto); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeSocialActionWithRequest("error", "request_parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	var proxyResponseProto pogo.ProxyResponseProto
@@ -497,12 +647,12 @@ func decodeSocialActionWithRequest(request []byte, payload []byte) string {
 	if err := proto.Unmarshal(payload, &proxyResponseProto); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeSocialActionWithRequest("error", "response_parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if proxyResponseProto.Status != pogo.ProxyResponseProto_COMPLETED && proxyResponseProto.Status != pogo.ProxyResponseProto_COMPLETED_AND_REASSIGNED {
 		statsCollector.IncDecodeSocialActionWithRequest("error", "non_success")
-		return fmt.Sprintf("unsuccessful proxyResponseProto response %d %s", int(proxyResponseProto.Status), proxyResponseProto.Status)
+		return fmt.Sprintf("unsuccessful proxyResponseProto response %d %s", int(proxyResponseProto.Status), proxyResponseProto.Status), errDecodeIgnored
 	}
 
 	switch pogo.InternalSocialAction(proxyRequestProto.GetAction()) {
@@ -516,22 +666,22 @@ func decodeSocialActionWithRequest(request []byte, payload []byte) string {
 	}
 
 	statsCollector.IncDecodeSocialActionWithRequest("ok", "unknown")
-	return fmt.Sprintf("Did not process %s", pogo.InternalSocialAction(proxyRequestProto.GetAction()).String())
+	return fmt.Sprintf("Did not process %s", pogo.InternalSocialAction(proxyRequestProto.GetAction()).String()), nil
 }
 
-func decodeGetFriendDetails(payload []byte) string {
+func decodeGetFriendDetails(payload []byte) (string, error) {
 	var getFriendDetailsOutProto pogo.InternalGetFriendDetailsOutProto
 	getFriendDetailsError := proto.Unmarshal(payload, &getFriendDetailsOutProto)
 
 	if getFriendDetailsError != nil {
 		statsCollector.IncDecodeGetFriendDetails("error", "parse")
 		log.Errorf("Failed to parse %s", getFriendDetailsError)
-		return fmt.Sprintf("Failed to parse %s", getFriendDetailsError)
+		return fmt.Sprintf("Failed to parse %s", getFriendDetailsError), parseFailure(getFriendDetailsError)
 	}
 
 	if getFriendDetailsOutProto.GetResult() != pogo.InternalGetFriendDetailsOutProto_SUCCESS || getFriendDetailsOutProto.GetFriend() == nil {
 		statsCollector.IncDecodeGetFriendDetails("error", "non_success")
-		return fmt.Sprintf("unsuccessful get friends details")
+		return fmt.Sprintf("unsuccessful get friends details"), errDecodeIgnored
 	}
 
 	failures := 0
@@ -546,22 +696,22 @@ func decodeGetFriendDetails(payload []byte) string {
 	}
 
 	statsCollector.IncDecodeGetFriendDetails("ok", "")
-	return fmt.Sprintf("%d players decoded on %d", len(getFriendDetailsOutProto.GetFriend())-failures, len(getFriendDetailsOutProto.GetFriend()))
+	return fmt.Sprintf("%d players decoded on %d", len(getFriendDetailsOutProto.GetFriend())-failures, len(getFriendDetailsOutProto.GetFriend())), nil
 }
 
-func decodeSearchPlayer(proxyRequestProto *pogo.ProxyRequestProto, payload []byte) string {
+func decodeSearchPlayer(proxyRequestProto *pogo.ProxyRequestProto, payload []byte) (string, error) {
 	var searchPlayerOutProto pogo.InternalSearchPlayerOutProto
 	searchPlayerOutError := proto.Unmarshal(payload, &searchPlayerOutProto)
 
 	if searchPlayerOutError != nil {
 		log.Errorf("Failed to parse %s", searchPlayerOutError)
 		statsCollector.IncDecodeSearchPlayer("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", searchPlayerOutError)
+		return fmt.Sprintf("Failed to parse %s", searchPlayerOutError), parseFailure(searchPlayerOutError)
 	}
 
 	if searchPlayerOutProto.GetResult() != pogo.InternalSearchPlayerOutProto_SUCCESS || searchPlayerOutProto.GetPlayer() == nil {
 		statsCollector.IncDecodeSearchPlayer("error", "non_success")
-		return fmt.Sprintf("unsuccessful search player response")
+		return fmt.Sprintf("unsuccessful search player response"), errDecodeIgnored
 	}
 
 	var searchPlayerProto pogo.InternalSearchPlayerProto
@@ -569,54 +719,54 @@ func decodeSearchPlayer(proxyRequestProto *pogo.ProxyRequestProto, payload []byt
 
 	if searchPlayerError != nil || searchPlayerProto.GetFriendCode() == "" {
 		statsCollector.IncDecodeSearchPlayer("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", searchPlayerError)
+		return fmt.Sprintf("Failed to parse %s", searchPlayerError), parseFailure(searchPlayerError)
 	}
 
 	player := searchPlayerOutProto.GetPlayer()
 	updatePlayerError := decoder.UpdatePlayerRecordWithPlayerSummary(dbDetails, player, player.PublicData, searchPlayerProto.GetFriendCode(), "")
 	if updatePlayerError != nil {
 		statsCollector.IncDecodeSearchPlayer("error", "update")
-		return fmt.Sprintf("Failed update player %s", updatePlayerError)
+		return fmt.Sprintf("Failed update player %s", updatePlayerError), updatePlayerError
 	}
 
 	statsCollector.IncDecodeSearchPlayer("ok", "")
-	return fmt.Sprintf("1 player decoded from SearchPlayerProto")
+	return fmt.Sprintf("1 player decoded from SearchPlayerProto"), nil
 }
 
-func decodeFortDetails(ctx context.Context, sDec []byte) string {
+func decodeFortDetails(ctx context.Context, sDec []byte, timestampMs int64) (string, error) {
 	decodedFort := &pogo.FortDetailsOutProto{}
 	if err := proto.Unmarshal(sDec, decodedFort); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeFortDetails("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	switch decodedFort.FortType {
 	case pogo.FortType_CHECKPOINT:
 		statsCollector.IncDecodeFortDetails("ok", "pokestop")
-		return decoder.UpdatePokestopRecordWithFortDetailsOutProto(ctx, dbDetails, decodedFort)
+		return decoder.UpdatePokestopRecordWithFortDetailsOutProto(ctx, dbDetails, decodedFort, timestampMs), nil
 	case pogo.FortType_GYM:
 		statsCollector.IncDecodeFortDetails("ok", "gym")
-		return decoder.UpdateGymRecordWithFortDetailsOutProto(ctx, dbDetails, decodedFort)
+		return decoder.UpdateGymRecordWithFortDetailsOutProto(ctx, dbDetails, decodedFort, timestampMs), nil
 	}
 
 	statsCollector.IncDecodeFortDetails("ok", "unknown")
-	return "Unknown fort type"
+	return "Unknown fort type", nil
 }
 
-func decodeGetMapForts(ctx context.Context, sDec []byte) string {
+func decodeGetMapForts(ctx context.Context, sDec []byte, timestampMs int64) (string, error) {
 	decodedMapForts := &pogo.GetMapFortsOutProto{}
 	if err := proto.Unmarshal(sDec, decodedMapForts); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeGetMapForts("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if decodedMapForts.Status != pogo.GetMapFortsOutProto_SUCCESS {
 		statsCollector.IncDecodeGetMapForts("error", "non_success")
 		res := fmt.Sprintf(`GetMapFortsOutProto: Ignored non-success value %d:%s`, decodedMapForts.Status,
 			pogo.GetMapFortsOutProto_Status_name[int32(decodedMapForts.Status)])
-		return res
+		return res, errDecodeIgnored
 	}
 
 	statsCollector.IncDecodeGetMapForts("ok", "")
@@ -624,7 +774,7 @@ func decodeGetMapForts(ctx context.Context, sDec []byte) string {
 	processedForts := 0
 
 	for _, fort := range decodedMapForts.Fort {
-		status, output := decoder.UpdateFortRecordWithGetMapFortsOutProto(ctx, dbDetails, fort)
+		status, output := decoder.UpdateFortRecordWithGetMapFortsOutProto(ctx, dbDetails, fort, timestampMs)
 		if status {
 			processedForts += 1
 			outputString += output + ", "
@@ -632,19 +782,19 @@ func decodeGetMapForts(ctx context.Context, sDec []byte) string {
 	}
 
 	if processedForts > 0 {
-		return fmt.Sprintf("Updated %d forts: %s", processedForts, outputString)
+		return fmt.Sprintf("Updated %d forts: %s", processedForts, outputString), nil
 	}
-	return "No forts updated"
+	return "No forts updated", nil
 }
 
-func decodeGetRoutes(payload []byte) string {
+func decodeGetRoutes(payload []byte) (string, error) {
 	getRoutesOutProto := &pogo.GetRoutesOutProto{}
 	if err := proto.Unmarshal(payload, getRoutesOutProto); err != nil {
-		return fmt.Sprintf("failed to decode GetRoutesOutProto %s", err)
+		return fmt.Sprintf("failed to decode GetRoutesOutProto %s", err), parseFailure(err)
 	}
 
 	if getRoutesOutProto.Status != pogo.GetRoutesOutProto_SUCCESS {
-		return fmt.Sprintf("GetRoutesOutProto: Ignored non-success value %d:%s", getRoutesOutProto.Status, getRoutesOutProto.Status.String())
+		return fmt.Sprintf("GetRoutesOutProto: Ignored non-success value %d:%s", getRoutesOutProto.Status, getRoutesOutProto.Status.String()), errDecodeIgnored
 	}
 
 	decodeSuccesses := map[string]bool{}
@@ -675,128 +825,130 @@ func decodeGetRoutes(payload []byte) string {
 		len(decodeSuccesses),
 		len(decodeErrors),
 		len(getRoutesOutProto.GetRouteMapCell()),
-	)
+	), nil
 }
 
-func decodeGetGymInfo(ctx context.Context, sDec []byte) string {
+func decodeGetGymInfo(ctx context.Context, sDec []byte, timestampMs int64) (string, error) {
 	decodedGymInfo := &pogo.GymGetInfoOutProto{}
 	if err := proto.Unmarshal(sDec, decodedGymInfo); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeGetGymInfo("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if decodedGymInfo.Result != pogo.GymGetInfoOutProto_SUCCESS {
 		statsCollector.IncDecodeGetGymInfo("error", "non_success")
 		res := fmt.Sprintf(`GymGetInfoOutProto: Ignored non-success value %d:%s`, decodedGymInfo.Result,
 			pogo.GymGetInfoOutProto_Result_name[int32(decodedGymInfo.Result)])
-		return res
+		return res, errDecodeIgnored
 	}
 
 	statsCollector.IncDecodeGetGymInfo("ok", "")
-	return decoder.UpdateGymRecordWithGymInfoProto(ctx, dbDetails, decodedGymInfo)
+	return decoder.UpdateGymRecordWithGymInfoProto(ctx, dbDetails, decodedGymInfo, timestampMs), nil
 }
 
-func decodeEncounter(ctx context.Context, sDec []byte, username string) string {
+func decodeEncounter(ctx context.Context, sDec []byte, username string, timestampMs int64) (string, error) {
 	decodedEncounterInfo := &pogo.EncounterOutProto{}
 	if err := proto.Unmarshal(sDec, decodedEncounterInfo); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeEncounter("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if decodedEncounterInfo.Status != pogo.EncounterOutProto_ENCOUNTER_SUCCESS {
 		statsCollector.IncDecodeEncounter("error", "non_success")
 		res := fmt.Sprintf(`GymGetInfoOutProto: Ignored non-success value %d:%s`, decodedEncounterInfo.Status,
 			pogo.EncounterOutProto_Status_name[int32(decodedEncounterInfo.Status)])
-		return res
+		return res, errDecodeIgnored
 	}
 
 	statsCollector.IncDecodeEncounter("ok", "")
-	return decoder.UpdatePokemonRecordWithEncounterProto(ctx, dbDetails, decodedEncounterInfo, username)
+	return decoder.UpdatePokemonRecordWithEncounterProto(ctx, dbDetails, decodedEncounterInfo, username, timestampMs), nil
 }
 
-func decodeDiskEncounter(ctx context.Context, sDec []byte, username string) string {
+func decodeDiskEncounter(ctx context.Context, sDec []byte, username string, timestampMs int64) (string, error) {
 	decodedEncounterInfo := &pogo.DiskEncounterOutProto{}
 	if err := proto.Unmarshal(sDec, decodedEncounterInfo); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeDiskEncounter("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if decodedEncounterInfo.Result != pogo.DiskEncounterOutProto_SUCCESS {
 		statsCollector.IncDecodeDiskEncounter("error", "non_success")
 		res := fmt.Sprintf(`DiskEncounterOutProto: Ignored non-success value %d:%s`, decodedEncounterInfo.Result,
 			pogo.DiskEncounterOutProto_Result_name[int32(decodedEncounterInfo.Result)])
-		return res
+		return res, errDecodeIgnored
 	}
 
 	statsCollector.IncDecodeDiskEncounter("ok", "")
-	return decoder.UpdatePokemonRecordWithDiskEncounterProto(ctx, dbDetails, decodedEncounterInfo, username)
+	return decoder.UpdatePokemonRecordWithDiskEncounterProto(ctx, dbDetails, decodedEncounterInfo, username, timestampMs), nil
 }
 
-func decodeStartIncident(ctx context.Context, sDec []byte) string {
+func decodeStartIncident(ctx context.Context, sDec []byte) (string, error) {
 	decodedIncident := &pogo.StartIncidentOutProto{}
 	if err := proto.Unmarshal(sDec, decodedIncident); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeStartIncident("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if decodedIncident.Status != pogo.StartIncidentOutProto_SUCCESS {
 		statsCollector.IncDecodeStartIncident("error", "non_success")
 		res := fmt.Sprintf(`GiovanniOutProto: Ignored non-success value %d:%s`, decodedIncident.Status,
 			pogo.StartIncidentOutProto_Status_name[int32(decodedIncident.Status)])
-		return res
+		return res, errDecodeIgnored
 	}
 
 	statsCollector.IncDecodeStartIncident("ok", "")
-	return decoder.ConfirmIncident(ctx, dbDetails, decodedIncident)
+	return decoder.ConfirmIncident(ctx, dbDetails, decodedIncident), nil
 }
 
-func decodeOpenInvasion(ctx context.Context, request []byte, payload []byte) string {
+func decodeOpenInvasion(ctx context.Context, request []byte, payload []byte) (string, error) {
 	decodeOpenInvasionRequest := &pogo.OpenInvasionCombatSessionProto{}
 
 	if err := proto.Unmarshal(request, decodeOpenInvasionRequest); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeOpenInvasion("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 	if decodeOpenInvasionRequest.IncidentLookup == nil {
-		return "Invalid OpenInvasionCombatSessionProto received"
+		return "Invalid OpenInvasionCombatSessionProto received", errDecodeIgnored
 	}
 
 	decodedOpenInvasionResponse := &pogo.OpenInvasionCombatSessionOutProto{}
 	if err := proto.Unmarshal(payload, decodedOpenInvasionResponse); err != nil {
 		log.Errorf("Failed to parse %s", err)
 		statsCollector.IncDecodeOpenInvasion("error", "parse")
-		return fmt.Sprintf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if decodedOpenInvasionResponse.Status != pogo.InvasionStatus_SUCCESS {
 		statsCollector.IncDecodeOpenInvasion("error", "non_success")
 		res := fmt.Sprintf(`InvasionLineupOutProto: Ignored non-success value %d:%s`, decodedOpenInvasionResponse.Status,
 			pogo.InvasionStatus_Status_name[int32(decodedOpenInvasionResponse.Status)])
-		return res
+		return res, errDecodeIgnored
 	}
 
 	statsCollector.IncDecodeOpenInvasion("ok", "")
-	return decoder.UpdateIncidentLineup(ctx, dbDetails, decodeOpenInvasionRequest, decodedOpenInvasionResponse)
+	return decoder.UpdateIncidentLineup(ctx, dbDetails, decodeOpenInvasionRequest, decodedOpenInvasionResponse), nil
 }
 
-func decodeGMO(ctx context.Context, protoData *ProtoData, scanParameters decoder.ScanParameters) string {
+func decodeGMO(ctx context.Context, protoData *ProtoData, scanParameters decoder.ScanParameters) (string, error) {
 	decodedGmo := &pogo.GetMapObjectsOutProto{}
 
 	if err := proto.Unmarshal(protoData.Data, decodedGmo); err != nil {
 		statsCollector.IncDecodeGMO("error", "parse")
 		log.Errorf("Failed to parse %s", err)
+		return fmt.Sprintf("Failed to parse %s", err), parseFailure(err)
 	}
 
 	if decodedGmo.Status != pogo.GetMapObjectsOutProto_SUCCESS {
 		statsCollector.IncDecodeGMO("error", "non_success")
+		decoder.RecordAccountNonSuccess(protoData.Account, getMethodName(int(pogo.Method_METHOD_GET_MAP_OBJECTS), true))
 		res := fmt.Sprintf(`GetMapObjectsOutProto: Ignored non-success value %d:%s`, decodedGmo.Status,
 			pogo.GetMapObjectsOutProto_Status_name[int32(decodedGmo.Status)])
-		return res
+		return res, errDecodeIgnored
 	}
 
 	var newForts []decoder.RawFortData
@@ -807,34 +959,57 @@ func decodeGMO(ctx context.Context, protoData *ProtoData, scanParameters decoder
 	var newClientWeather []decoder.RawClientWeatherData
 	var newMapCells []uint64
 	var cellsToBeCleaned []uint64
+	var scannedCells []uint64
 
+	// checked before stale cells are skipped, as it describes what this account can see
+	emptyGmo := true
 	for _, mapCell := range decodedGmo.MapCell {
+		if isCellNotEmpty(mapCell) {
+			emptyGmo = false
+			break
+		}
+	}
+	decoder.RecordAccountGmo(protoData.Account, emptyGmo)
+
+	now := time.Now()
+	for _, mapCell := range decodedGmo.MapCell {
+		// cells are aged against the GMO itself, which has already passed any max age check
+		cellAsAtMs := asAtTimeMs(mapCell.AsOfTimeMs, protoData.Timestamp, now)
+		if isOlderThanMaxAge(cellAsAtMs, protoData.Timestamp) {
+			statsCollector.IncStaleUpdates("gmo_cell")
+			continue
+		}
+		if isDuplicateGmoCell(mapCell.S2CellId, mapCell.AsOfTimeMs, protoData.ScanContext) {
+			continue
+		}
+		scannedCells = append(scannedCells, mapCell.S2CellId)
 		if isCellNotEmpty(mapCell) {
 			newMapCells = append(newMapCells, mapCell.S2CellId)
 			if cellContainsForts(mapCell) {
 				cellsToBeCleaned = append(cellsToBeCleaned, mapCell.S2CellId)
 			}
 		}
-		timestampMs := uint64(mapCell.AsOfTimeMs)
+		timestampMs := uint64(cellAsAtMs)
 		for _, fort := range mapCell.Fort {
-			newForts = append(newForts, decoder.RawFortData{Cell: mapCell.S2CellId, Data: fort})
+			newForts = append(newForts, decoder.RawFortData{Cell: mapCell.S2CellId, Data: fort, Timestamp: timestampMs})
 
 			if fort.ActivePokemon != nil {
-				newMapPokemon = append(newMapPokemon, decoder.RawMapPokemonData{Cell: mapCell.S2CellId, Data: fort.ActivePokemon})
+				newMapPokemon = append(newMapPokemon, decoder.RawMapPokemonData{Cell: mapCell.S2CellId, Data: fort.ActivePokemon, Timestamp: timestampMs})
 			}
 		}
 		for _, mon := range mapCell.WildPokemon {
 			newWildPokemon = append(newWildPokemon, decoder.RawWildPokemonData{Cell: mapCell.S2CellId, Data: mon, Timestamp: timestampMs})
 		}
 		for _, mon := range mapCell.NearbyPokemon {
-			newNearbyPokemon = append(newNearbyPokemon, decoder.RawNearbyPokemonData{Cell: mapCell.S2CellId, Data: mon})
+			newNearbyPokemon = append(newNearbyPokemon, decoder.RawNearbyPokemonData{Cell: mapCell.S2CellId, Data: mon, Timestamp: timestampMs})
 		}
 		for _, station := range mapCell.Stations {
-			newStations = append(newStations, decoder.RawStationData{Cell: mapCell.S2CellId, Data: station})
+			newStations = append(newStations, decoder.RawStationData{Cell: mapCell.S2CellId, Data: station, Timestamp: timestampMs})
 		}
 	}
+	// weather is not timestamped per cell, so is as at the time of the whole GMO
 	for _, clientWeather := range decodedGmo.ClientWeather {
-		newClientWeather = append(newClientWeather, decoder.RawClientWeatherData{Cell: clientWeather.S2CellId, Data: clientWeather})
+		newClientWeather = append(newClientWeather, decoder.RawClientWeatherData{Cell: clientWeather.S2CellId, Data: clientWeather, Timestamp: uint64(protoData.Timestamp)})
 	}
 
 	if scanParameters.ProcessGyms || scanParameters.ProcessPokestops {
@@ -842,6 +1017,7 @@ func decodeGMO(ctx context.Context, protoData *ProtoData, scanParameters decoder
 	}
 	if scanParameters.ProcessPokemon {
 		decoder.UpdatePokemonBatch(ctx, dbDetails, scanParameters, newWildPokemon, newNearbyPokemon, newMapPokemon, protoData.Account)
+		decoder.UpdateSpawnpointDisappearances(ctx, dbDetails, protoData.Lat, protoData.Lon, scannedCells, newWildPokemon, protoData.Timestamp)
 	}
 	if scanParameters.ProcessWeather {
 		decoder.UpdateClientWeatherBatch(ctx, dbDetails, newClientWeather)
@@ -878,7 +1054,22 @@ func decodeGMO(ctx context.Context, protoData *ProtoData, scanParameters decoder
 	statsCollector.AddDecodeGMOType("weather", float64(newClientWeatherLen))
 	statsCollector.AddDecodeGMOType("cell", float64(newMapCellsLen))
 
-	return fmt.Sprintf("%d cells containing %d forts %d stations %d mon %d nearby", newMapCellsLen, newFortsLen, newStationsLen, newWildPokemonLen, newNearbyPokemonLen)
+	return fmt.Sprintf("%d cells containing %d forts %d stations %d mon %d nearby", newMapCellsLen, newFortsLen, newStationsLen, newWildPokemonLen, newNearbyPokemonLen), nil
+}
+
+func decodeGetPlayer(account string, data []byte) (string, error) {
+	var decodedPlayer pogo.GetPlayerOutProto
+	if err := proto.Unmarshal(data, &decodedPlayer); err != nil {
+		log.Errorf("Failed to parse GetPlayerOutProto %s", err)
+		return fmt.Sprintf("Failed to parse GetPlayerOutProto %s", err), parseFailure(err)
+	}
+
+	if !decodedPlayer.Success {
+		decoder.RecordAccountNonSuccess(account, getMethodName(int(pogo.Method_METHOD_GET_PLAYER), true))
+		return "Ignored GetPlayerOutProto non-success", errDecodeIgnored
+	}
+
+	return decoder.UpdateAccountWithGetPlayer(account, &decodedPlayer), nil
 }
 
 func isCellNotEmpty(mapCell *pogo.ClientMapCellProto) bool {
@@ -889,66 +1080,66 @@ func cellContainsForts(mapCell *pogo.ClientMapCellProto) bool {
 	return len(mapCell.Fort) > 0
 }
 
-func decodeGetContestData(ctx context.Context, request []byte, data []byte) string {
+func decodeGetContestData(ctx context.Context, request []byte, data []byte, timestampMs int64) (string, error) {
 	var decodedContestData pogo.GetContestDataOutProto
 	if err := proto.Unmarshal(data, &decodedContestData); err != nil {
 		log.Errorf("Failed to parse GetContestDataOutProto %s", err)
-		return fmt.Sprintf("Failed to parse GetContestDataOutProto %s", err)
+		return fmt.Sprintf("Failed to parse GetContestDataOutProto %s", err), parseFailure(err)
 	}
 
 	var decodedContestDataRequest pogo.GetContestDataProto
 	if request != nil {
 		if err := proto.Unmarshal(request, &decodedContestDataRequest); err != nil {
 			log.Errorf("Failed to parse GetContestDataProto %s", err)
-			return fmt.Sprintf("Failed to parse GetContestDataProto %s", err)
+			return fmt.Sprintf("Failed to parse GetContestDataProto %s", err), parseFailure(err)
 		}
 	}
-	return decoder.UpdatePokestopWithContestData(ctx, dbDetails, &decodedContestDataRequest, &decodedContestData)
+	return decoder.UpdatePokestopWithContestData(ctx, dbDetails, &decodedContestDataRequest, &decodedContestData, timestampMs), nil
 }
 
-func decodeGetPokemonSizeContestEntry(ctx context.Context, request []byte, data []byte) string {
+func decodeGetPokemonSizeContestEntry(ctx context.Context, request []byte, data []byte, timestampMs int64) (string, error) {
 	var decodedPokemonSizeContestEntry pogo.GetPokemonSizeLeaderboardEntryOutProto
 	if err := proto.Unmarshal(data, &decodedPokemonSizeContestEntry); err != nil {
 		log.Errorf("Failed to parse GetPokemonSizeLeaderboardEntryOutProto %s", err)
-		return fmt.Sprintf("Failed to parse GetPokemonSizeLeaderboardEntryOutProto %s", err)
+		return fmt.Sprintf("Failed to parse GetPokemonSizeLeaderboardEntryOutProto %s", err), parseFailure(err)
 	}
 
 	if decodedPokemonSizeContestEntry.Status != pogo.GetPokemonSizeLeaderboardEntryOutProto_SUCCESS {
-		return fmt.Sprintf("Ignored GetPokemonSizeLeaderboardEntryOutProto non-success status %s", decodedPokemonSizeContestEntry.Status)
+		return fmt.Sprintf("Ignored GetPokemonSizeLeaderboardEntryOutProto non-success status %s", decodedPokemonSizeContestEntry.Status), errDecodeIgnored
 	}
 
 	var decodedPokemonSizeContestEntryRequest pogo.GetPokemonSizeLeaderboardEntryProto
 	if request != nil {
 		if err := proto.Unmarshal(request, &decodedPokemonSizeContestEntryRequest); err != nil {
 			log.Errorf("Failed to parse GetPokemonSizeLeaderboardEntryOutProto %s", err)
-			return fmt.Sprintf("Failed to parse GetPokemonSizeLeaderboardEntryOutProto %s", err)
+			return fmt.Sprintf("Failed to parse GetPokemonSizeLeaderboardEntryOutProto %s", err), parseFailure(err)
 		}
 	}
 
-	return decoder.UpdatePokestopWithPokemonSizeContestEntry(ctx, dbDetails, &decodedPokemonSizeContestEntryRequest, &decodedPokemonSizeContestEntry)
+	return decoder.UpdatePokestopWithPokemonSizeContestEntry(ctx, dbDetails, &decodedPokemonSizeContestEntryRequest, &decodedPokemonSizeContestEntry, timestampMs), nil
 }
 
-func decodeGetStationDetails(ctx context.Context, request []byte, data []byte) string {
+func decodeGetStationDetails(ctx context.Context, request []byte, data []byte, timestampMs int64) (string, error) {
 	var decodedGetStationDetails pogo.GetStationedPokemonDetailsOutProto
 	if err := proto.Unmarshal(data, &decodedGetStationDetails); err != nil {
 		log.Errorf("Failed to parse GetStationedPokemonDetailsOutProto %s", err)
-		return fmt.Sprintf("Failed to parse GetStationedPokemonDetailsOutProto %s", err)
+		return fmt.Sprintf("Failed to parse GetStationedPokemonDetailsOutProto %s", err), parseFailure(err)
 	}
 
 	var decodedGetStationDetailsRequest pogo.GetStationedPokemonDetailsProto
 	if request != nil {
 		if err := proto.Unmarshal(request, &decodedGetStationDetailsRequest); err != nil {
 			log.Errorf("Failed to parse GetStationedPokemonDetailsProto %s", err)
-			return fmt.Sprintf("Failed to parse GetStationedPokemonDetailsProto %s", err)
+			return fmt.Sprintf("Failed to parse GetStationedPokemonDetailsProto %s", err), parseFailure(err)
 		}
 	}
 
 	if decodedGetStationDetails.Result == pogo.GetStationedPokemonDetailsOutProto_STATION_NOT_FOUND {
 		// station without stationed pokemon found, therefore we need to reset the columns
-		return decoder.ResetStationedPokemonWithStationDetailsNotFound(ctx, dbDetails, &decodedGetStationDetailsRequest)
+		return decoder.ResetStationedPokemonWithStationDetailsNotFound(ctx, dbDetails, &decodedGetStationDetailsRequest, timestampMs), nil
 	} else if decodedGetStationDetails.Result != pogo.GetStationedPokemonDetailsOutProto_SUCCESS {
-		return fmt.Sprintf("Ignored GetStationedPokemonDetailsOutProto non-success status %s", decodedGetStationDetails.Result)
+		return fmt.Sprintf("Ignored GetStationedPokemonDetailsOutProto non-success status %s", decodedGetStationDetails.Result), errDecodeIgnored
 	}
 
-	return decoder.UpdateStationWithStationDetails(ctx, dbDetails, &decodedGetStationDetailsRequest, &decodedGetStationDetails)
+	return decoder.UpdateStationWithStationDetails(ctx, dbDetails, &decodedGetStationDetailsRequest, &decodedGetStationDetails, timestampMs), nil
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"hash/maphash"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+
+	"golbat/config"
+)
+
+// protoDedupCache holds a key for every proto (or GMO cell) claimed within the duplicate
+// window, so
+// identical data sent by several devices is only decoded once
+var protoDedupCache *ttlcache.Cache[string, struct{}]
+var protoDedupSeed = maphash.MakeSeed()
+
+func InitProtoDedup() {
+	window := time.Duration(config.Config.Tuning.DuplicateWindow) * time.Second
+	if window <= 0 {
+		return
+	}
+
+	protoDedupCache = ttlcache.New[string, struct{}](
+		ttlcache.WithTTL[string, struct{}](window),
+		ttlcache.WithDisableTouchOnHit[string, struct{}](), // window runs from when the data was first seen
+	)
+	go protoDedupCache.Start()
+}
+
+// protoDedupKey returns the duplicate key for a proto, from its method, scan context
+// and payload
+func protoDedupKey(method int, protoData *ProtoData) string {
+	return fmt.Sprintf("%d:%s:%x:%x", method, protoData.ScanContext,
+		maphash.Bytes(protoDedupSeed, protoData.Data), maphash.Bytes(protoDedupSeed, protoData.Request))
+}
+
+// claimProto claims a proto for decoding, returning false if a proto with the same method,
+// scan context and payload has already been claimed within the duplicate window. The claim
+// is taken atomically so concurrent workers cannot both decode the same data; the returned
+// key is passed to releaseProtoClaim if decoding does not succeed.
+func claimProto(method int, protoData *ProtoData) (string, bool) {
+	if protoDedupCache == nil {
+		return "", true
+	}
+
+	key := protoDedupKey(method, protoData)
+	if _, found := protoDedupCache.GetOrSet(key, struct{}{}); found {
+		statsCollector.IncDuplicateProtos(getMethodName(method, true))
+		return key, false
+	}
+	return key, true
+}
+
+// releaseProtoClaim removes the claim on a proto which was not decoded, so a copy from
+// another device can still be decoded
+func releaseProtoClaim(key string) {
+	if protoDedupCache == nil || key == "" {
+		return
+	}
+
+	protoDedupCache.Delete(key)
+}
+
+// isDuplicateGmoCell returns true if the GMO cell has already been seen with the same
+// AsOfTimeMs within the duplicate window, claiming it otherwise
+func isDuplicateGmoCell(cellId uint64, asOfTimeMs int64, scanContext string) bool {
+	if protoDedupCache == nil || asOfTimeMs == 0 {
+		return false
+	}
+
+	key := fmt.Sprintf("cell:%d:%s:%d", cellId, scanContext, asOfTimeMs)
+	if _, found := protoDedupCache.GetOrSet(key, struct{}{}); found {
+		statsCollector.IncDuplicateProtos("GMO_CELL")
+		return true
+	}
+	return false
+}
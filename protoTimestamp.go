@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+)
+
+// normaliseTimestampMs converts a sender timestamp to unix milliseconds, accepting seconds
+// from senders which do not send milliseconds
+func normaliseTimestampMs(timestamp int64) int64 {
+	if timestamp > 0 && timestamp < 1e11 {
+		return timestamp * 1000
+	}
+	return timestamp
+}
+
+// asAtTimeMs returns the time (unix milliseconds) data timestamped by the sender should be
+// saved as. Timestamps ahead of our clock by up to the clock skew are brought back to now;
+// any further ahead cannot be trusted and the fallback is used instead.
+func asAtTimeMs(timestampMs int64, fallbackMs int64, now time.Time) int64 {
+	nowMs := now.UnixMilli()
+	if timestampMs <= 0 {
+		return fallbackMs
+	}
+
+	if timestampMs > nowMs {
+		if timestampMs-nowMs > int64(config.Config.Tuning.ClockSkew)*1000 {
+			log.Debugf("Sender timestamp %d is %dms ahead, ignored", timestampMs, timestampMs-nowMs)
+			return fallbackMs
+		}
+		return nowMs
+	}
+	return timestampMs
+}
+
+// isOlderThanMaxAge returns true if data as at timestampMs was older than the configured
+// max age at referenceMs (both unix milliseconds)
+func isOlderThanMaxAge(timestampMs int64, referenceMs int64) bool {
+	maxAge := int64(config.Config.Tuning.MaxProtoAge) * 1000
+	return maxAge > 0 && referenceMs-timestampMs > maxAge
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"golbat/config"
+)
+
+var errRawBodyTooLarge = errors.New("body too large")
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// readRawBody reads a /raw request body, decompressing it according to Content-Encoding.
+// Both the body as sent and the decompressed body are limited to the configured maximum,
+// so a small compressed body cannot expand without bound.
+func readRawBody(r *http.Request) ([]byte, error) {
+	limit := int64(config.Config.Tuning.RawMaxBodySize) * 1048576
+	if limit <= 0 {
+		limit = 5 * 1048576
+	}
+
+	body, err := readLimited(r.Body, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return readLimited(reader, limit)
+	case "deflate":
+		// RFC 9110 deflate is zlib wrapped, but some clients send a raw deflate stream
+		reader, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(body))
+		}
+		defer reader.Close()
+		return readLimited(reader, limit)
+	case "zstd":
+		reader, err := zstd.NewReader(bytes.NewReader(body),
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(uint64(limit)))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return readLimited(reader, limit)
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedEncoding, encoding)
+	}
+}
+
+// readLimited reads everything from reader, failing with errRawBodyTooLarge rather than
+// truncating if there is more than limit bytes
+func readLimited(reader io.Reader, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+			return nil, errRawBodyTooLarge
+		}
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, errRawBodyTooLarge
+	}
+	return body, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	"golbat/decoder"
+)
+
+const defaultRawCredentialName = "raw_bearer"
+
+var errOutsideCredentialScope = errors.New("outside permitted scope")
+
+// authoriseRawToken finds the credential matching a bearer token, returning its name. The
+// credential is nil when the shared raw_bearer was used, or when no raw authentication is
+// configured at all (in which case every sender is accepted).
+func authoriseRawToken(token string) (string, *config.RawCredential, bool) {
+	if config.Config.RawBearer == "" && len(config.Config.RawCredentials) == 0 {
+		return "", nil, true
+	}
+	if config.Config.RawBearer != "" && tokenEqual(token, config.Config.RawBearer) {
+		return defaultRawCredentialName, nil, true
+	}
+	for i := range config.Config.RawCredentials {
+		credential := &config.Config.RawCredentials[i]
+		if credential.Token != "" && tokenEqual(token, credential.Token) {
+			return credential.Name, credential, true
+		}
+	}
+	return "", nil, false
+}
+
+func tokenEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// scopeRawRequest checks a request against the contexts and areas permitted by its
+// credential, returning the scan context the data should be processed with. Data outside
+// the permitted scope is moved to the credential's downgrade context, so the scan rules for
+// that context decide what is processed, or rejected if there is no downgrade context.
+func scopeRawRequest(name string, credential *config.RawCredential, scanContext string, lat, lon float64) (string, error) {
+	if credential == nil {
+		if name != "" {
+			statsCollector.IncRawCredentialRequests(name, "ok")
+		}
+		return scanContext, nil
+	}
+
+	if decoder.RawCredentialInScope(credential, scanContext, lat, lon) {
+		statsCollector.IncRawCredentialRequests(name, "ok")
+		return scanContext, nil
+	}
+
+	if credential.DowngradeContext != "" {
+		statsCollector.IncRawCredentialRequests(name, "downgraded")
+		log.Debugf("Raw: credential %s out of scope (context '%s' at %f,%f), downgraded to '%s'",
+			name, scanContext, lat, lon, credential.DowngradeContext)
+		return credential.DowngradeContext, nil
+	}
+
+	statsCollector.IncRawCredentialRequests(name, "rejected")
+	log.Infof("Raw: credential %s out of scope (context '%s' at %f,%f), rejected", name, scanContext, lat, lon)
+	return "", errOutsideCredentialScope
+}
@@ -0,0 +1,114 @@
+package rawparser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// contentsParser handles the generic format used by most clients, an object describing the
+// device with the protos in a contents array
+type contentsParser struct{}
+
+type contentsBody struct {
+	HaveAr      *bool             `json:"have_ar"`
+	Uuid        string            `json:"uuid"`
+	Username    string            `json:"username"`
+	TrainerLvl  number            `json:"trainerlvl"`
+	ScanContext string            `json:"scan_context"`
+	LatTarget   number            `json:"lat_target"`
+	LonTarget   number            `json:"lon_target"`
+	Timestamp   number            `json:"timestamp"`
+	Contents    []json.RawMessage `json:"contents"`
+}
+
+// contentsEntry accepts either data or payload for the proto, and method or type for the
+// method, as clients differ
+type contentsEntry struct {
+	Data    *string `json:"data"`
+	Payload *string `json:"payload"`
+	Method  number  `json:"method"`
+	Type    number  `json:"type"`
+	Request string  `json:"request"`
+	HaveAr  *bool   `json:"have_ar"`
+}
+
+func (contentsParser) Name() string {
+	return "contents"
+}
+
+func (contentsParser) Detect(req *Request) bool {
+	return req.firstByte() == '{'
+}
+
+func (contentsParser) Parse(req *Request) (*Submission, error) {
+	var body contentsBody
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		return nil, parseErrorf("invalid json: %s", err)
+	}
+	if body.Contents == nil {
+		return nil, parseErrorf("missing contents")
+	}
+
+	submission := &Submission{
+		Uuid:        body.Uuid,
+		Account:     body.Username,
+		Level:       30,
+		ScanContext: body.ScanContext,
+		Lat:         body.LatTarget.value,
+		Lon:         body.LonTarget.value,
+		HaveAr:      body.HaveAr,
+		Timestamp:   int64(body.Timestamp.value),
+	}
+	if body.TrainerLvl.valid {
+		submission.Level = int(body.TrainerLvl.value)
+	}
+
+	for i, rawEntry := range body.Contents {
+		field := fmt.Sprintf("contents[%d]", i)
+		proto, err := parseContentsEntry(field, rawEntry)
+		if err != nil {
+			submission.Skipped = append(submission.Skipped, err.Error())
+			continue
+		}
+		submission.Protos = append(submission.Protos, proto)
+	}
+
+	return submission, nil
+}
+
+func parseContentsEntry(field string, rawEntry json.RawMessage) (Proto, error) {
+	var entry contentsEntry
+	if err := json.Unmarshal(rawEntry, &entry); err != nil {
+		return Proto{}, parseErrorf("%s: invalid entry: %s", field, err)
+	}
+
+	payload := entry.Data
+	if payload == nil {
+		payload = entry.Payload
+	}
+	method := entry.Method
+	if !method.valid {
+		method = entry.Type
+	}
+	if payload == nil {
+		return Proto{}, parseErrorf("%s: missing data", field)
+	}
+	if !method.valid {
+		return Proto{}, parseErrorf("%s: missing method", field)
+	}
+
+	proto := Proto{
+		Method: int(method.value),
+		HaveAr: entry.HaveAr,
+	}
+	var err error
+	if proto.Data, err = decodeBase64(field+".data", *payload); err != nil {
+		return Proto{}, err
+	}
+	if entry.Request != "" {
+		if proto.Request, err = decodeBase64(field+".request", entry.Request); err != nil {
+			return Proto{}, err
+		}
+	}
+	return proto, nil
+}
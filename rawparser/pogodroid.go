@@ -0,0 +1,99 @@
+package rawparser
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golbat/pogo"
+)
+
+// pogodroidParser handles Pogodroid, which sends an array of protos and identifies the
+// device in the origin header
+type pogodroidParser struct{}
+
+type pogodroidEntry struct {
+	Payload    *string         `json:"payload"`
+	Type       number          `json:"type"`
+	Lat        number          `json:"lat"`
+	Lng        number          `json:"lng"`
+	QuestsHeld json.RawMessage `json:"quests_held"`
+}
+
+func (pogodroidParser) Name() string {
+	return "pogodroid"
+}
+
+func (pogodroidParser) Detect(req *Request) bool {
+	return req.Header.Get("origin") != "" || req.firstByte() == '['
+}
+
+func (pogodroidParser) Parse(req *Request) (*Submission, error) {
+	var rawEntries []json.RawMessage
+	if err := json.Unmarshal(req.Body, &rawEntries); err != nil {
+		return nil, parseErrorf("invalid json: %s", err)
+	}
+
+	submission := &Submission{
+		Uuid:    req.Header.Get("origin"),
+		Account: "Pogodroid",
+		Level:   30,
+	}
+
+	for i, rawEntry := range rawEntries {
+		field := fmt.Sprintf("[%d]", i)
+		var entry pogodroidEntry
+		if err := json.Unmarshal(rawEntry, &entry); err != nil {
+			submission.Skipped = append(submission.Skipped, fmt.Sprintf("%s: invalid entry: %s", field, err))
+			continue
+		}
+		if entry.Payload == nil {
+			submission.Skipped = append(submission.Skipped, field+": missing payload")
+			continue
+		}
+		if !entry.Type.valid {
+			submission.Skipped = append(submission.Skipped, field+": missing type")
+			continue
+		}
+		data, err := decodeBase64(field+".payload", *entry.Payload)
+		if err != nil {
+			submission.Skipped = append(submission.Skipped, err.Error())
+			continue
+		}
+
+		// the first entry with a location locates the whole submission
+		if submission.Lat == 0 && submission.Lon == 0 && entry.Lat.value != 0 && entry.Lng.value != 0 {
+			submission.Lat = entry.Lat.value
+			submission.Lon = entry.Lng.value
+		}
+
+		submission.Protos = append(submission.Protos, Proto{
+			Method: int(entry.Type.value),
+			Data:   data,
+			HaveAr: questsHeldHasARTask(entry.QuestsHeld),
+		})
+	}
+
+	return submission, nil
+}
+
+// questsHeldHasARTask reports whether the quests held include an AR scan, or nil if the
+// list is absent or not a list of quest types
+func questsHeldHasARTask(questsHeld json.RawMessage) *bool {
+	if len(questsHeld) == 0 || string(questsHeld) == "null" {
+		return nil
+	}
+
+	var questTypes []float64
+	if err := json.Unmarshal(questsHeld, &questTypes); err != nil {
+		return nil
+	}
+
+	res := false
+	for _, questType := range questTypes {
+		if int64(questType) == int64(pogo.QuestType_QUEST_GEOTARGETED_AR_SCAN) {
+			res = true
+			break
+		}
+	}
+	return &res
+}
@@ -0,0 +1,140 @@
+// Package rawparser turns the bodies sent to /raw by the various scanner clients into a
+// common submission. Each client format is a Parser, chosen by header, user agent or the
+// shape of the body.
+package rawparser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Request is a received /raw body along with the headers used to detect its format
+type Request struct {
+	Header http.Header
+	Body   []byte
+}
+
+func (r *Request) UserAgent() string {
+	return r.Header.Get("User-Agent")
+}
+
+// firstByte returns the first non-whitespace byte of the body, or 0 if empty
+func (r *Request) firstByte() byte {
+	trimmed := bytes.TrimLeft(r.Body, " \t\r\n")
+	if len(trimmed) == 0 {
+		return 0
+	}
+	return trimmed[0]
+}
+
+// Submission is the normalised content of a /raw body
+type Submission struct {
+	Uuid        string
+	Account     string
+	Level       int
+	ScanContext string
+	Lat         float64
+	Lon         float64
+	HaveAr      *bool
+	Timestamp   int64 // when the sender saw the data, 0 if not sent
+	Protos      []Proto
+	Skipped     []string // the reason each malformed proto was left out
+}
+
+// Proto is a single proto within a submission. HaveAr, when set, overrides the
+// submission's value.
+type Proto struct {
+	Method  int
+	Data    []byte
+	Request []byte
+	HaveAr  *bool
+}
+
+type Parser interface {
+	// Name identifies the format in logs and metrics
+	Name() string
+	// Detect returns true if the request is in this parser's format
+	Detect(req *Request) bool
+	// Parse converts the body, returning a ParseError if the body as a whole is malformed.
+	// Malformed protos within it are left out and listed in Skipped, for the caller to
+	// reject.
+	Parse(req *Request) (*Submission, error)
+}
+
+// ParseError is returned when a body is in a recognised format but is malformed
+type ParseError struct {
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return e.Reason
+}
+
+func parseErrorf(format string, args ...any) error {
+	return &ParseError{Reason: fmt.Sprintf(format, args...)}
+}
+
+var ErrUnknownFormat = errors.New("unrecognised raw format")
+
+var parsers []Parser
+
+// Register adds a parser. Parsers are tried in the order registered, so more specific
+// formats should be registered before more general ones.
+func Register(parser Parser) {
+	parsers = append(parsers, parser)
+}
+
+func init() {
+	Register(pogodroidParser{})
+	Register(contentsParser{})
+}
+
+// Parse finds the parser for a request and uses it to convert the body, returning the
+// name of the parser used
+func Parse(req *Request) (string, *Submission, error) {
+	for _, parser := range parsers {
+		if !parser.Detect(req) {
+			continue
+		}
+		submission, err := parser.Parse(req)
+		return parser.Name(), submission, err
+	}
+	return "", nil, ErrUnknownFormat
+}
+
+// number accepts a JSON number or a string holding one, as some clients quote numeric
+// fields. Any other value is treated as absent.
+type number struct {
+	value float64
+	valid bool
+}
+
+func (n *number) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case float64:
+		*n = number{value: v, valid: true}
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			*n = number{value: f, valid: true}
+		}
+	}
+	return nil
+}
+
+func decodeBase64(field string, value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, parseErrorf("%s: invalid base64: %s", field, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,198 @@
+package rawparser
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func request(body string, header map[string]string) *Request {
+	req := &Request{Header: http.Header{}, Body: []byte(body)}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestParseContents(t *testing.T) {
+	req := request(`{
+		"uuid": "device1",
+		"username": "account1",
+		"trainerlvl": 35,
+		"scan_context": "quest",
+		"have_ar": false,
+		"lat_target": 51.5,
+		"lon_target": -0.1,
+		"contents": [
+			{"data": "AQI=", "method": 106},
+			{"payload": "AwQ=", "type": 104, "request": "BQ==", "have_ar": true}
+		]
+	}`, nil)
+
+	format, submission, err := Parse(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if format != "contents" {
+		t.Errorf("format = %s, want contents", format)
+	}
+
+	haveAr := true
+	noAr := false
+	want := &Submission{
+		Uuid:        "device1",
+		Account:     "account1",
+		Level:       35,
+		ScanContext: "quest",
+		Lat:         51.5,
+		Lon:         -0.1,
+		HaveAr:      &noAr,
+		Protos: []Proto{
+			{Method: 106, Data: []byte{1, 2}},
+			{Method: 104, Data: []byte{3, 4}, Request: []byte{5}, HaveAr: &haveAr},
+		},
+	}
+	if !reflect.DeepEqual(submission, want) {
+		t.Errorf("submission = %+v, want %+v", submission, want)
+	}
+}
+
+func TestParseContentsDefaultLevel(t *testing.T) {
+	_, submission, err := Parse(request(`{"contents": []}`, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if submission.Level != 30 {
+		t.Errorf("level = %d, want 30", submission.Level)
+	}
+}
+
+func TestParsePogodroid(t *testing.T) {
+	body := `[
+		{"payload": "AQI=", "type": 106, "lat": 0, "lng": 0},
+		{"payload": "AwQ=", "type": 2, "lat": 40.1, "lng": -70.2, "quests_held": []}
+	]`
+
+	for name, req := range map[string]*Request{
+		"origin header": request(body, map[string]string{"origin": "device2"}),
+		"array shape":   request(body, nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			format, submission, err := Parse(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if format != "pogodroid" {
+				t.Errorf("format = %s, want pogodroid", format)
+			}
+			if submission.Uuid != req.Header.Get("origin") || submission.Account != "Pogodroid" || submission.Level != 30 {
+				t.Errorf("unexpected submission details %+v", submission)
+			}
+			if submission.Lat != 40.1 || submission.Lon != -70.2 {
+				t.Errorf("location = %f,%f, want 40.1,-70.2", submission.Lat, submission.Lon)
+			}
+			if len(submission.Protos) != 2 || submission.Protos[0].HaveAr != nil ||
+				submission.Protos[1].HaveAr == nil || *submission.Protos[1].HaveAr {
+				t.Errorf("unexpected protos %+v", submission.Protos)
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := map[string]struct {
+		body   string
+		header map[string]string
+		reason string
+	}{
+		"pogodroid not a list": {`{"payload": "AQI="}`, map[string]string{"origin": "d"}, ""},
+		"contents missing":     {`{"uuid": "d"}`, nil, "missing contents"},
+		"contents not a list":  {`{"contents": {}}`, nil, ""},
+		"contents bad json":    {`{"contents": [}`, nil, ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, submission, err := Parse(request(test.body, test.header))
+			var parseError *ParseError
+			if !errors.As(err, &parseError) {
+				t.Fatalf("err = %v, want ParseError", err)
+			}
+			if submission != nil {
+				t.Errorf("submission = %+v, want nil", submission)
+			}
+			if test.reason != "" && parseError.Reason != test.reason {
+				t.Errorf("reason = %q, want %q", parseError.Reason, test.reason)
+			}
+		})
+	}
+}
+
+func TestParseSkipsMalformedEntries(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		header  map[string]string
+		skipped []string
+	}{
+		"pogodroid payload wrong type": {`[{"payload": 5, "type": 106}, {"payload": "AQI=", "type": 106}]`, map[string]string{"origin": "d"}, nil},
+		"pogodroid missing payload":    {`[{"type": 106}, {"payload": "AQI=", "type": 106}]`, nil, []string{"[0]: missing payload"}},
+		"pogodroid missing type":       {`[{"payload": "AQI="}, {"payload": "AQI=", "type": 106}]`, nil, []string{"[0]: missing type"}},
+		"contents missing data":        {`{"contents": [{"method": 106}, {"data": "AQI=", "method": 106}]}`, nil, []string{"contents[0]: missing data"}},
+		"contents missing method":      {`{"contents": [{"data": "AQI="}, {"data": "AQI=", "method": 106}]}`, nil, []string{"contents[0]: missing method"}},
+		"contents not an object":       {`{"contents": ["AQI=", {"data": "AQI=", "method": 106}]}`, nil, nil},
+		"contents bad base64":          {`{"contents": [{"data": "!!", "method": 2}, {"data": "AQI=", "method": 106}]}`, nil, nil},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, submission, err := Parse(request(test.body, test.header))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(submission.Protos) != 1 || submission.Protos[0].Method != 106 {
+				t.Errorf("protos = %+v, want the single valid proto", submission.Protos)
+			}
+			if len(submission.Skipped) != 1 {
+				t.Fatalf("skipped = %q, want one entry", submission.Skipped)
+			}
+			if test.skipped != nil && !reflect.DeepEqual(submission.Skipped, test.skipped) {
+				t.Errorf("skipped = %q, want %q", submission.Skipped, test.skipped)
+			}
+		})
+	}
+}
+
+func TestParseNumericStrings(t *testing.T) {
+	_, submission, err := Parse(request(`{
+		"trainerlvl": "32",
+		"timestamp": "1700000000000",
+		"lat_target": "51.5",
+		"lon_target": -0.1,
+		"contents": [{"data": "AQI=", "method": "106"}]
+	}`, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if submission.Level != 32 || submission.Timestamp != 1700000000000 || submission.Lat != 51.5 || submission.Lon != -0.1 {
+		t.Errorf("unexpected submission details %+v", submission)
+	}
+	if len(submission.Protos) != 1 || submission.Protos[0].Method != 106 {
+		t.Errorf("protos = %+v, want method 106", submission.Protos)
+	}
+
+	// a level which is not a number is ignored, as before typed parsing
+	_, submission, err = Parse(request(`{"trainerlvl": "high", "contents": []}`, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if submission.Level != 30 {
+		t.Errorf("level = %d, want 30", submission.Level)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, _, err := Parse(request("not json", nil)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("err = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"golbat/config"
+)
+
+// RecordedProto is a single normalised proto as written to a recording, one per line.
+// Byte fields are base64 encoded by encoding/json.
+type RecordedProto struct {
+	Received    int64   `json:"received"` // unix milliseconds
+	Method      int     `json:"method"`
+	Request     []byte  `json:"request,omitempty"`
+	Response    []byte  `json:"response"`
+	Account     string  `json:"account"`
+	Level       int     `json:"level"`
+	Uuid        string  `json:"uuid"`
+	ScanContext string  `json:"scan_context"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+	HaveAr      *bool   `json:"have_ar"`
+	UserAgent   string  `json:"user_agent,omitempty"`
+	Timestamp   int64   `json:"timestamp,omitempty"` // sender timestamp, unix milliseconds
+}
+
+func (r *RecordedProto) ProtoData() ProtoData {
+	return ProtoData{
+		Method:      r.Method,
+		Data:        r.Response,
+		Request:     r.Request,
+		HaveAr:      r.HaveAr,
+		Account:     r.Account,
+		Level:       r.Level,
+		Uuid:        r.Uuid,
+		ScanContext: r.ScanContext,
+		Lat:         r.Lat,
+		Lon:         r.Lon,
+		UserAgent:   r.UserAgent,
+		Timestamp:   r.Timestamp,
+	}
+}
+
+type rawRecorder struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	output  *lumberjack.Logger
+}
+
+var protoRecorder *rawRecorder
+
+// StartRawRecorder opens the recording file if the recorder is enabled in config
+func StartRawRecorder() {
+	cfg := config.Config.Recorder
+	if !cfg.Enabled {
+		return
+	}
+
+	output := &lumberjack.Logger{
+		Filename:   filepath.ToSlash(cfg.Filename),
+		MaxSize:    cfg.MaxSize, // MB
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+	}
+	protoRecorder = &rawRecorder{
+		encoder: json.NewEncoder(output),
+		output:  output,
+	}
+	log.Infof("Recording received protos to %s", cfg.Filename)
+}
+
+// Record writes each proto in a submission to the recording. It is safe to call on a
+// nil recorder, which records nothing.
+func (r *rawRecorder) Record(protoData []ProtoData, received time.Time) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, entry := range protoData {
+		err := r.encoder.Encode(RecordedProto{
+			Received:    received.UnixMilli(),
+			Method:      entry.Method,
+			Request:     entry.Request,
+			Response:    entry.Data,
+			Account:     entry.Account,
+			Level:       entry.Level,
+			Uuid:        entry.Uuid,
+			ScanContext: entry.ScanContext,
+			Lat:         entry.Lat,
+			Lon:         entry.Lon,
+			HaveAr:      entry.HaveAr,
+			UserAgent:   entry.UserAgent,
+			Timestamp:   entry.Timestamp,
+		})
+		if err != nil {
+			log.Errorf("Recorder: failed to write proto: %s", err)
+			return
+		}
+	}
+}
+
+func (r *rawRecorder) Close() {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_ = r.output.Close()
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"golbat/config"
+	db2 "golbat/db"
+	"golbat/decoder"
+	"golbat/stats_collector"
+	"golbat/webhooks"
+)
+
+// runReplay implements `golbat replay [flags] <recording>...`, feeding recordings made by
+// the raw recorder back through decode
+func runReplay(args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	database := flags.String("db", "", "database name to replay into (defaults to the configured database)")
+	realtime := flags.Bool("realtime", false, "replay with the original timing between protos")
+	sendWebhooks := flags.Bool("webhooks", false, "send webhooks to the configured destinations")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: golbat replay [flags] <recording>...\n")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	logLevel := log.InfoLevel
+	if cfg.Logging.Debug {
+		logLevel = log.DebugLevel
+	}
+	SetupLogger(logLevel, false, cfg.Logging.MaxSize, cfg.Logging.MaxAge, cfg.Logging.MaxBackups, cfg.Logging.Compress)
+
+	if *database != "" {
+		config.Config.Database.Db = *database
+	}
+	if !*sendWebhooks {
+		config.Config.Webhooks = nil
+	}
+	// never record a replay
+	config.Config.Recorder.Enabled = false
+
+	var wg sync.WaitGroup
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		watchForShutdown(ctx, cancelFn)
+	}()
+
+	webhooksSender, err := webhooks.NewWebhooksSender(config.Config)
+	if err != nil {
+		log.Fatalf("failed to setup webhooks sender: %s", err)
+	}
+	decoder.SetWebhooksSender(webhooksSender)
+
+	log.Infof("Replay into database %s", config.Config.Database.Db)
+	db, err = openDatabase()
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	dbDetails = db2.DbDetails{
+		PokemonDb:       db,
+		UsePokemonCache: true,
+		GeneralDb:       db,
+	}
+
+	decoder.SetKojiUrl(cfg.Koji.Url, cfg.Koji.BearerToken)
+	statsCollector = stats_collector.NewNoopStatsCollector()
+	decoder.SetStatsCollector(statsCollector)
+	db2.SetStatsCollector(statsCollector)
+	decoder.InitialiseOhbem()
+	decoder.LoadStatsGeofences()
+	InitProtoDedup()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := webhooksSender.Run(ctx); err != nil {
+			log.Errorf("failed to start webhooks sender: %s", err)
+		}
+	}()
+
+	startTime := time.Now()
+	total := 0
+	for _, filename := range flags.Args() {
+		count, err := replayFile(ctx, filename, *realtime)
+		total += count
+		if err != nil {
+			log.Errorf("Replay: %s: %s", filename, err)
+			break
+		}
+		log.Infof("Replay: %s: %d protos", filename, count)
+	}
+	log.Infof("Replay: decoded %d protos in %s", total, time.Since(startTime))
+
+	cancelFn()
+	wg.Wait()
+	webhooksSender.Flush()
+}
+
+// replayFile decodes every proto in a single recording, which may be gzipped as written
+// by a rotated recorder. Returns the number of protos decoded.
+func replayFile(ctx context.Context, filename string, realtime bool) (int, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(filename, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	scanner := bufio.NewScanner(reader)
+	// Protos such as GMOs can be large; allow up to the same limit as the raw endpoint
+	// after base64 expansion
+	scanner.Buffer(make([]byte, 0, 1048576), 8*1048576)
+
+	count := 0
+	var lastReceived int64
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return count, ctx.Err()
+		}
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var recorded RecordedProto
+		if err := json.Unmarshal(line, &recorded); err != nil {
+			return count, fmt.Errorf("line %d: %w", count+1, err)
+		}
+
+		if realtime && lastReceived != 0 && recorded.Received > lastReceived {
+			select {
+			case <-ctx.Done():
+				return count, ctx.Err()
+			case <-time.After(time.Duration(recorded.Received-lastReceived) * time.Millisecond):
+			}
+		}
+		lastReceived = recorded.Received
+
+		// not live, so recorded protos are not dropped for their age and failures are not
+		// kept as dead letters, as the recording still holds them
+		decodeProtoBatch([]ProtoData{recorded.ProtoData()}, time.UnixMilli(recorded.Received), false)
+		count++
+	}
+
+	return count, scanner.Err()
+}
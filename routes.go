@@ -2,11 +2,10 @@ package main
 
 import (
 	"context"
-	b64 "encoding/base64"
-	"encoding/json"
-	"io"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +15,7 @@ import (
 	"golbat/decoder"
 	"golbat/geo"
 	"golbat/pogo"
+	"golbat/rawparser"
 )
 
 type ProtoData struct {
@@ -29,37 +29,8 @@ type ProtoData struct {
 	ScanContext string
 	Lat         float64
 	Lon         float64
-}
-
-type InboundRawData struct {
-	Base64Data string
-	Request    string
-	Method     int
-	HaveAr     *bool
-}
-
-func questsHeldHasARTask(quests_held any) *bool {
-	const ar_quest_id = int64(pogo.QuestType_QUEST_GEOTARGETED_AR_SCAN)
-
-	quests_held_list, ok := quests_held.([]any)
-	if !ok {
-		log.Errorf("Raw: unexpected quests_held type in data: %T", quests_held)
-		return nil
-	}
-	for _, quest_id := range quests_held_list {
-		if quest_id_f, ok := quest_id.(float64); ok {
-			if int64(quest_id_f) == ar_quest_id {
-				res := true
-				return &res
-			}
-			continue
-		}
-		// quest_id is not float64? Treat the whole thing as unknown.
-		log.Errorf("Raw: unexpected quest_id type in quests_held: %T", quest_id)
-		return nil
-	}
-	res := false
-	return &res
+	UserAgent   string
+	Timestamp   int64 // unix milliseconds when the sender saw the data, 0 if unknown
 }
 
 func Raw(c *gin.Context) {
@@ -67,18 +38,34 @@ func Raw(c *gin.Context) {
 	var r *http.Request = c.Request
 
 	authHeader := r.Header.Get("Authorization")
-	if config.Config.RawBearer != "" {
-		if authHeader != "Bearer "+config.Config.RawBearer {
-			statsCollector.IncRawRequests("error", "auth")
-			log.Errorf("Raw: Incorrect authorisation received (%s)", authHeader)
-			return
-		}
+	bearerToken := ""
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		bearerToken = strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	credentialName, credential, authorised := authoriseRawToken(bearerToken)
+	if !authorised {
+		statsCollector.IncRawRequests("error", "auth")
+		log.Errorf("Raw: Incorrect authorisation received (%s)", authHeader)
+		return
 	}
 
-	body, err := io.ReadAll(io.LimitReader(r.Body, 5*1048576))
+	body, err := readRawBody(r)
 	if err != nil {
-		statsCollector.IncRawRequests("error", "io_error")
-		log.Errorf("Raw: Error (1) during HTTP receive %s", err)
+		switch {
+		case errors.Is(err, errRawBodyTooLarge):
+			statsCollector.IncRawRequests("error", "too_large")
+			log.Warnf("Raw: Body larger than %d MiB (Content-Encoding '%s')",
+				config.Config.Tuning.RawMaxBodySize, r.Header.Get("Content-Encoding"))
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case errors.Is(err, errUnsupportedEncoding):
+			statsCollector.IncRawRequests("error", "encoding")
+			log.Warnf("Raw: %s", err)
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		default:
+			statsCollector.IncRawRequests("error", "io_error")
+			log.Errorf("Raw: Error (1) during HTTP receive %s", err)
+			w.WriteHeader(http.StatusBadRequest)
+		}
 		return
 	}
 	if err := r.Body.Close(); err != nil {
@@ -87,214 +74,112 @@ func Raw(c *gin.Context) {
 		return
 	}
 
-	decodeError := false
-	uuid := ""
-	account := ""
-	level := 30
-	scanContext := ""
-	var latTarget, lonTarget float64
-	var globalHaveAr *bool
-	var protoData []InboundRawData
-
-	// Objective is to normalise incoming proto data. Unfortunately each provider seems
-	// to be just different enough that this ends up being a little bit more of a mess
-	// than I would like
-
-	pogodroidHeader := r.Header.Get("origin")
 	userAgent := r.Header.Get("User-Agent")
 
-	//log.Infof("Raw: Received data from %s", body)
-	//log.Infof("User agent is %s", userAgent)
-
-	if pogodroidHeader != "" {
-		var raw []map[string]interface{}
-		if err := json.Unmarshal(body, &raw); err != nil {
-			decodeError = true
-		} else {
-			for _, entry := range raw {
-				if latTarget == 0 && lonTarget == 0 {
-					lat := entry["lat"]
-					lng := entry["lng"]
-					if lat != nil && lng != nil {
-						lat_f, _ := lat.(float64)
-						lng_f, _ := lng.(float64)
-						if lat_f != 0 && lng_f != 0 {
-							latTarget = lat_f
-							lonTarget = lng_f
-						}
-					}
-				}
-				protoData = append(protoData, InboundRawData{
-					Base64Data: entry["payload"].(string),
-					Method:     int(entry["type"].(float64)),
-					HaveAr: func() *bool {
-						if v := entry["quests_held"]; v != nil {
-							return questsHeldHasARTask(v)
-						}
-						return nil
-					}(),
-				})
-			}
-		}
-		uuid = pogodroidHeader
-		account = "Pogodroid"
-	} else {
-		var raw map[string]interface{}
-		if err := json.Unmarshal(body, &raw); err != nil {
-			decodeError = true
-		} else {
-			if v := raw["have_ar"]; v != nil {
-				res, ok := v.(bool)
-				if ok {
-					globalHaveAr = &res
-				}
-			}
-			if v := raw["uuid"]; v != nil {
-				uuid, _ = v.(string)
-			}
-			if v := raw["username"]; v != nil {
-				account, _ = v.(string)
-			}
-			if v := raw["trainerlvl"]; v != nil {
-				lvl, ok := v.(float64)
-				if ok {
-					level = int(lvl)
-				}
-			}
-			if v := raw["scan_context"]; v != nil {
-				scanContext, _ = v.(string)
-			}
+	// Objective is to normalise incoming proto data. Each provider's format is handled by a
+	// parser in rawparser, chosen by header or the shape of the body
+	format, submission, err := rawparser.Parse(&rawparser.Request{Header: r.Header, Body: body})
+	if err != nil {
+		statsCollector.IncRawRequests("error", "decode")
+		log.Infof("Raw: Data could not be decoded (%s). From User agent %s - Received data %s", err, userAgent, body)
 
-			if v := raw["lat_target"]; v != nil {
-				latTarget, _ = v.(float64)
-			}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"format": format, "error": err.Error()})
+		return
+	}
 
-			if v := raw["lon_target"]; v != nil {
-				lonTarget, _ = v.(float64)
-			}
+	// A malformed entry rejects the whole submission, so the sender learns of it rather than
+	// having part of the data silently left out
+	if len(submission.Skipped) > 0 {
+		statsCollector.IncRawRequests("error", "malformed")
+		statsCollector.AddRawSkippedProtos(format, float64(len(submission.Skipped)))
+		log.Infof("Raw: Rejected %d malformed protos (%s). From User agent %s",
+			len(submission.Skipped), strings.Join(submission.Skipped, ", "), userAgent)
 
-			contents, ok := raw["contents"].([]interface{})
-			if !ok {
-				decodeError = true
-
-			} else {
-
-				decodeAlternate := func(data map[string]interface{}, key1, key2 string) interface{} {
-					if v := data[key1]; v != nil {
-						return v
-					}
-					if v := data[key2]; v != nil {
-						return v
-					}
-					return nil
-				}
-
-				for _, v := range contents {
-					entry := v.(map[string]interface{})
-					// Try to decode the payload automatically without requiring any knowledge of the
-					// provider type
-
-					b64data := decodeAlternate(entry, "data", "payload")
-					method := decodeAlternate(entry, "method", "type")
-					request := entry["request"]
-					haveAr := entry["have_ar"]
-
-					if method == nil || b64data == nil {
-						log.Errorf("Error decoding raw")
-						continue
-					}
-					inboundRawData := InboundRawData{
-						Base64Data: func() string {
-							if res, ok := b64data.(string); ok {
-								return res
-							}
-							return ""
-						}(),
-						Method: func() int {
-							if res, ok := method.(float64); ok {
-								return int(res)
-							}
-
-							return 0
-						}(),
-						Request: func() string {
-							if request != nil {
-								res, ok := request.(string)
-								if ok {
-									return res
-								}
-							}
-							return ""
-						}(),
-						HaveAr: func() *bool {
-							if haveAr != nil {
-								res, ok := haveAr.(bool)
-								if ok {
-									return &res
-								}
-							}
-							return nil
-						}(),
-					}
-
-					protoData = append(protoData, inboundRawData)
-				}
-			}
-		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"format": format, "error": "malformed protos", "skipped": submission.Skipped})
+		return
 	}
 
-	if decodeError == true {
-		statsCollector.IncRawRequests("error", "decode")
-		log.Infof("Raw: Data could not be decoded. From User agent %s - Received data %s", userAgent, body)
-
+	uuid := submission.Uuid
+	latTarget, lonTarget := submission.Lat, submission.Lon
+	scanContext, err := scopeRawRequest(credentialName, credential, submission.ScanContext, latTarget, lonTarget)
+	if err != nil {
+		statsCollector.IncRawRequests("error", "scope")
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.WriteHeader(http.StatusForbidden)
 		return
 	}
 
-	// Process each proto in a packet in sequence, but in a go-routine
-	go func() {
-		timeout := 5 * time.Second
-		if config.Config.Tuning.ExtendedTimeout {
-			timeout = 30 * time.Second
+	decodeList := make([]ProtoData, 0, len(submission.Protos))
+	for _, entry := range submission.Protos {
+		haveAr := submission.HaveAr
+		if entry.HaveAr != nil {
+			haveAr = entry.HaveAr
 		}
 
-		for _, entry := range protoData {
-			method := entry.Method
-			payload := entry.Base64Data
-			request := entry.Request
+		decodeList = append(decodeList, ProtoData{
+			Method:      entry.Method,
+			Data:        entry.Data,
+			Request:     entry.Request,
+			Account:     submission.Account,
+			Level:       submission.Level,
+			HaveAr:      haveAr,
+			Uuid:        uuid,
+			Lat:         latTarget,
+			Lon:         lonTarget,
+			ScanContext: scanContext,
+			UserAgent:   userAgent,
+			Timestamp:   normaliseTimestampMs(submission.Timestamp),
+		})
+	}
 
-			haveAr := globalHaveAr
-			if entry.HaveAr != nil {
-				haveAr = entry.HaveAr
-			}
+	// Protos in a packet are processed in sequence by a decode worker. In synchronous mode
+	// the response waits for the worker and reports the outcome of each proto
+	var results <-chan []DecodeResult
+	if isSyncRawRequest(c) {
+		results, err = rawDecodeQueue.EnqueueAndWait("http", decodeList)
+	} else {
+		err = rawDecodeQueue.Enqueue("http", decodeList)
+	}
+	if errors.Is(err, errDecodeQueueClosed) {
+		statsCollector.IncRawRequests("error", "shutdown")
+		log.Warnf("Raw: Shutting down, rejecting %d protos from %s", len(decodeList), uuid)
 
-			protoData := ProtoData{
-				Account:     account,
-				Level:       level,
-				HaveAr:      haveAr,
-				Uuid:        uuid,
-				Lat:         latTarget,
-				Lon:         lonTarget,
-				ScanContext: scanContext,
-			}
-			protoData.Data, _ = b64.StdEncoding.DecodeString(payload)
-			if request != "" {
-				protoData.Request, _ = b64.StdEncoding.DecodeString(request)
-			}
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		statsCollector.IncRawRequests("error", "queue_full")
+		log.Warnf("Raw: Decode queue full, rejecting %d protos from %s", len(decodeList), uuid)
 
-			// provide independent cancellation contexts for each proto decode
-			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			decode(ctx, method, &protoData)
-			cancel()
-		}
-	}()
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
 
 	if latTarget != 0 && lonTarget != 0 && uuid != "" {
-		UpdateDeviceLocation(uuid, latTarget, lonTarget, scanContext)
+		UpdateDeviceLocation(uuid, latTarget, lonTarget, scanContext, submission.Account)
 	}
 
 	statsCollector.IncRawRequests("ok", "")
+
+	if results != nil {
+		select {
+		case decodeResults := <-results:
+			c.JSON(http.StatusOK, decodeResults)
+		case <-rawDecodeQueue.Done():
+			// the results may have been delivered as the last worker exited
+			select {
+			case decodeResults := <-results:
+				c.JSON(http.StatusOK, decodeResults)
+			default:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case <-r.Context().Done():
+		}
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusCreated)
 	//if err := json.NewEncoder(w).Encode(t); err != nil {
@@ -302,6 +187,16 @@ func Raw(c *gin.Context) {
 	//}
 }
 
+// isSyncRawRequest returns true if the sender asked for the raw request to be decoded
+// before responding, by either the `sync` query parameter or the X-Golbat-Sync header
+func isSyncRawRequest(c *gin.Context) bool {
+	if sync, err := strconv.ParseBool(c.Query("sync")); err == nil && sync {
+		return true
+	}
+	sync, err := strconv.ParseBool(c.GetHeader("X-Golbat-Sync"))
+	return err == nil && sync
+}
+
 func AuthRequired() gin.HandlerFunc {
 	return func(context *gin.Context) {
 		if config.Config.ApiSecret != "" {
@@ -475,6 +370,307 @@ func GetPokestop(c *gin.Context) {
 	c.JSON(http.StatusAccepted, pokestop)
 }
 
+func PokestopScan(c *gin.Context) {
+	if !config.Config.TestFortInMemory {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "test_fort_in_memory is not enabled"})
+		return
+	}
+
+	var requestBody decoder.ApiPokestopScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/pokestop/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	res := decoder.GetPokestopInArea(ctx, dbDetails, requestBody)
+	cancel()
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+func GetGym(c *gin.Context) {
+	gymId := c.Param("gym_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	gym, err := decoder.GetGymWithDefenders(ctx, dbDetails, gymId)
+	cancel()
+	if err != nil {
+		log.Warnf("GET /api/gym/id/:gym_id/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if gym == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gym)
+}
+
+func GymScan(c *gin.Context) {
+	if !config.Config.TestFortInMemory {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "test_fort_in_memory is not enabled"})
+		return
+	}
+
+	var requestBody decoder.ApiGymScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/gym/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	res := decoder.GetGymInArea(ctx, dbDetails, requestBody)
+	cancel()
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+func RaidScan(c *gin.Context) {
+	if !config.Config.TestFortInMemory {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "test_fort_in_memory is not enabled"})
+		return
+	}
+
+	var requestBody decoder.ApiRaidScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/raids/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	res := decoder.GetRaidsInArea(ctx, dbDetails, requestBody)
+	cancel()
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+func IncidentScan(c *gin.Context) {
+	if !config.Config.TestFortInMemory {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "test_fort_in_memory is not enabled"})
+		return
+	}
+
+	var requestBody decoder.ApiIncidentScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/incidents/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if requestBody.Summary {
+		c.JSON(http.StatusAccepted, decoder.GetIncidentSummaryInArea(ctx, dbDetails, requestBody))
+		return
+	}
+	c.JSON(http.StatusAccepted, decoder.GetIncidentsInArea(ctx, dbDetails, requestBody))
+}
+
+func StationScan(c *gin.Context) {
+	var requestBody decoder.ApiStationScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/stations/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	res := decoder.GetStationsInArea(ctx, dbDetails, requestBody)
+	cancel()
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+func RouteScan(c *gin.Context) {
+	var requestBody decoder.ApiRouteScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/routes/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	routes, err := decoder.GetRoutesInArea(ctx, dbDetails, requestBody)
+	if err != nil {
+		log.Warnf("POST /api/routes/scan/ Error during route scan %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusAccepted, routes)
+}
+
+func WeatherScan(c *gin.Context) {
+	var requestBody decoder.ApiWeatherScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/weather/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	weather, err := decoder.GetWeatherInArea(ctx, dbDetails, requestBody)
+	if errors.Is(err, decoder.ErrWeatherAreaTooLarge) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Warnf("POST /api/weather/scan/ Error during weather scan %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusAccepted, weather)
+}
+
+const weatherHistoryDefaultHours = 24
+
+// GetWeatherHistory lists the weather condition changes over the last ?hours= (default 24),
+// optionally for a single ?cell_id=
+func GetWeatherHistory(c *gin.Context) {
+	hours, err := strconv.Atoi(c.Query("hours"))
+	if err != nil || hours <= 0 {
+		hours = weatherHistoryDefaultHours
+	}
+	var cellId int64
+	if cell := c.Query("cell_id"); cell != "" {
+		if cellId, err = strconv.ParseInt(cell, 10, 64); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	history, err := decoder.GetWeatherHistory(ctx, dbDetails, hours, cellId)
+	if err != nil {
+		log.Warnf("GET /api/weather/history/ Error during weather history %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+func SpawnpointScan(c *gin.Context) {
+	var requestBody decoder.ApiSpawnpointScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/spawnpoints/scan/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	spawnpoints, err := decoder.GetSpawnpointsInArea(ctx, dbDetails, requestBody)
+	if err != nil {
+		log.Warnf("POST /api/spawnpoints/scan/ Error during spawnpoint scan %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusAccepted, spawnpoints)
+}
+
+const upcomingSpawnsMaxWindow = 24 * 60 * 60
+
+// UpcomingSpawns predicts the spawns in the area of the body between ?from= and ?to= (unix
+// timestamps), defaulting to the next hour
+func UpcomingSpawns(c *gin.Context) {
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		from = time.Now().Unix()
+	}
+	to, err := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err != nil {
+		to = from + 60*60
+	}
+	if to < from || to-from > upcomingSpawnsMaxWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from, and within 24 hours of it"})
+		return
+	}
+
+	var requestBody decoder.ApiSpawnpointScan
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/spawnpoints/upcoming/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	spawns, err := decoder.GetUpcomingSpawnsInArea(ctx, dbDetails, requestBody, from, to)
+	if err != nil {
+		log.Warnf("POST /api/spawnpoints/upcoming/ Error during spawn prediction %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusAccepted, spawns)
+}
+
+func EncounterQueue(c *gin.Context) {
+	var requestBody decoder.ApiEncounterQueueRequest
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		log.Warnf("POST /api/queue/encounter/ Error during post retrieve %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if requestBody.Device == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "device is required to lease encounters"})
+		return
+	}
+
+	queue, err := decoder.GetEncounterQueue(requestBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, queue)
+}
+
+func GetAccounts(c *gin.Context) {
+	c.JSON(http.StatusOK, decoder.GetAccounts(c.Query("flagged") == "true"))
+}
+
+func GetAccount(c *gin.Context) {
+	account := decoder.GetAccount(c.Param("username"))
+	if account == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
 func GetDevices(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"devices": GetAllDevices()})
 }
+
+// SetDeviceAreas sets the areas a device is expected to scan, as a list of area names in
+// the same form as the config
+func SetDeviceAreas(c *gin.Context) {
+	var areas []string
+	if err := c.BindJSON(&areas); err != nil {
+		log.Warnf("POST /api/devices/id/:device_id/areas cannot decode request body %v", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if !SetDeviceAssignedAreas(c.Param("device_id"), areas) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
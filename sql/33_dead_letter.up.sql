@@ -0,0 +1,21 @@
+CREATE TABLE `dead_letter`
+(
+    `id`           bigint unsigned     NOT NULL AUTO_INCREMENT,
+    `method`       int                 NOT NULL,
+    `error`        varchar(512)        NOT NULL,
+    `uuid`         varchar(100)        NOT NULL,
+    `account`      varchar(100)        NOT NULL,
+    `user_agent`   varchar(255)        NOT NULL,
+    `scan_context` varchar(100)        NOT NULL,
+    `level`        tinyint unsigned    NOT NULL,
+    `lat`          double(18, 14)      NOT NULL,
+    `lon`          double(18, 14)      NOT NULL,
+    `have_ar`      tinyint(1) unsigned DEFAULT NULL,
+    `request`      mediumblob          DEFAULT NULL,
+    `payload`      mediumblob          NOT NULL,
+    `received`     int unsigned        NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `ix_method` (`method`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
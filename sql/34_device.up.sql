@@ -0,0 +1,20 @@
+CREATE TABLE `device`
+(
+    `id`                varchar(100)     NOT NULL,
+    `lat`               double(18, 14)   NOT NULL,
+    `lon`               double(18, 14)   NOT NULL,
+    `scan_context`      varchar(100)     NOT NULL,
+    `account`           varchar(100)     NOT NULL,
+    `areas`             varchar(1024)    NOT NULL,
+    `assigned_areas`    varchar(1024)    NOT NULL,
+    `last_method`       varchar(100)     NOT NULL,
+    `protos_per_minute` int unsigned     NOT NULL,
+    `silent`            tinyint(1)       NOT NULL DEFAULT 0,
+    `out_of_area`       tinyint(1)       NOT NULL DEFAULT 0,
+    `first_seen`        int unsigned     NOT NULL,
+    `last_update`       int unsigned     NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `ix_last_update` (`last_update`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
@@ -0,0 +1,23 @@
+CREATE TABLE `gym_defender`
+(
+    `gym_id`             varchar(35)       NOT NULL,
+    `slot`               tinyint unsigned  NOT NULL,
+    `pokemon_id`         smallint unsigned NOT NULL,
+    `form`               smallint unsigned NOT NULL,
+    `costume`            smallint unsigned NOT NULL,
+    `gender`             tinyint unsigned  NOT NULL,
+    `shiny`              tinyint(1)        NOT NULL DEFAULT 0,
+    `cp_now`             int unsigned      NOT NULL,
+    `cp_when_deployed`   int unsigned      NOT NULL,
+    `motivation`         double            NOT NULL,
+    `deployed_timestamp` int unsigned      NOT NULL,
+    `trainer_name`       varchar(15)       NOT NULL,
+    `trainer_level`      tinyint unsigned  NOT NULL,
+    `times_fed`          int unsigned      NOT NULL,
+    `battles_won`        int unsigned      NOT NULL,
+    `battles_lost`       int unsigned      NOT NULL,
+    `updated`            int unsigned      NOT NULL,
+    PRIMARY KEY (`gym_id`, `slot`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
@@ -0,0 +1,12 @@
+CREATE TABLE `weather_history`
+(
+    `id`                 bigint              NOT NULL,
+    `changed`            int unsigned        NOT NULL,
+    `gameplay_condition` tinyint unsigned    DEFAULT NULL,
+    `severity`           tinyint unsigned    DEFAULT NULL,
+    `warn_weather`       tinyint(1) unsigned DEFAULT NULL,
+    PRIMARY KEY (`id`, `changed`),
+    KEY `ix_changed` (`changed`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE spawnpoint
+    ADD COLUMN `seen_minutes` bigint unsigned NOT NULL DEFAULT 0;
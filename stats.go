@@ -147,6 +147,17 @@ func StartStatsExpiry(db *sqlx.DB) {
 				log.Infof("DB - Cleanup of pokemon_area_stats table took %s (%d rows)", elapsed, rows)
 			}
 
+			start = time.Now()
+			result, err = db.Exec("DELETE FROM weather_history WHERE `changed` < UNIX_TIMESTAMP() - ?;", config.Config.Cleanup.StatsDays*24*60*60)
+			elapsed = time.Since(start)
+
+			if err != nil {
+				log.Errorf("DB - Cleanup of weather_history table error %s", err)
+			} else {
+				rows, _ := result.RowsAffected()
+				log.Infof("DB - Cleanup of weather_history table took %s (%d rows)", elapsed, rows)
+			}
+
 			tables := []string{"pokemon_stats", "pokemon_shiny_stats", "pokemon_iv_stats", "pokemon_hundo_stats", "pokemon_nundo_stats", "invasion_stats", "quest_stats", "raid_stats"}
 
 			for _, table := range tables {
@@ -12,6 +12,11 @@ type noopCollector struct {
 }
 
 func (col *noopCollector) IncRawRequests(string, string)                         {}
+func (col *noopCollector) AddRawSkippedProtos(string, float64)                   {}
+func (col *noopCollector) IncRawCredentialRequests(string, string)               {}
+func (col *noopCollector) SetDecodeQueueDepth(float64)                           {}
+func (col *noopCollector) UpdateDecodeQueueWait(float64)                         {}
+func (col *noopCollector) IncDecodeQueueDrops(string)                            {}
 func (col *noopCollector) IncDecodeMethods(string, string, string)               {}
 func (col *noopCollector) IncDecodeFortDetails(string, string)                   {}
 func (col *noopCollector) IncDecodeGetMapForts(string, string)                   {}
@@ -40,6 +45,8 @@ func (col *noopCollector) UpdateRaidCount([]geo.AreaName, int64)
 func (col *noopCollector) UpdateFortCount([]geo.AreaName, string, string)        {}
 func (col *noopCollector) UpdateIncidentCount([]geo.AreaName)                    {}
 func (col *noopCollector) IncDuplicateEncounters(bool)                           {}
+func (col *noopCollector) IncDuplicateProtos(string)                             {}
+func (col *noopCollector) IncStaleUpdates(string)                                {}
 func (col *noopCollector) IncDbQuery(string, error)                              {}
 func (col *noopCollector) SetGyms(int8, bool, float64)                           {}
 func (col *noopCollector) SetRaids(int64, float64)                               {}
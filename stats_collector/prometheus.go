@@ -24,6 +24,45 @@ var (
 		},
 		[]string{"status", "message"},
 	)
+	rawSkippedProtos = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "raw_skipped_protos",
+			Help:      "Total number of malformed protos left out of otherwise valid raw submissions",
+		},
+		[]string{"format"},
+	)
+	rawCredentialRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "raw_credential_requests",
+			Help:      "Total number of raw requests by credential and scope status",
+		},
+		[]string{"credential", "status"},
+	)
+	decodeQueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "decode_queue_depth",
+			Help:      "Current number of raw submissions waiting for a decode worker",
+		},
+	)
+	decodeQueueWait = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "decode_queue_wait_seconds",
+			Help:      "Time raw submissions spent waiting for a decode worker",
+			Buckets:   []float64{.001, .005, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		},
+	)
+	decodeQueueDrops = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "decode_queue_drops",
+			Help:      "Total number of raw submissions rejected because the decode queue was full",
+		},
+		[]string{"source"},
+	)
 
 	decodeMethods = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -260,6 +299,22 @@ var (
 		},
 		[]string{"sameacct"},
 	)
+	duplicateProtos = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "duplicate_protos",
+			Help:      "Total number of duplicate protos skipped before decoding",
+		},
+		[]string{"method"},
+	)
+	staleUpdates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "stale_updates",
+			Help:      "Total number of protos, GMO cells and records refused as older than the data already held",
+		},
+		[]string{"type"},
+	)
 	dbQueries = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: ns,
@@ -329,6 +384,26 @@ func (col *promCollector) IncRawRequests(status, message string) {
 	rawRequests.WithLabelValues(status, message).Inc()
 }
 
+func (col *promCollector) AddRawSkippedProtos(format string, value float64) {
+	rawSkippedProtos.WithLabelValues(format).Add(value)
+}
+
+func (col *promCollector) IncRawCredentialRequests(credential, status string) {
+	rawCredentialRequests.WithLabelValues(credential, status).Inc()
+}
+
+func (col *promCollector) SetDecodeQueueDepth(depth float64) {
+	decodeQueueDepth.Set(depth)
+}
+
+func (col *promCollector) UpdateDecodeQueueWait(seconds float64) {
+	decodeQueueWait.Observe(seconds)
+}
+
+func (col *promCollector) IncDecodeQueueDrops(source string) {
+	decodeQueueDrops.WithLabelValues(source).Inc()
+}
+
 func (col *promCollector) IncDecodeMethods(status, message, method string) {
 	decodeMethods.WithLabelValues(status, message, method).Inc()
 }
@@ -484,6 +559,14 @@ func (col *promCollector) IncDuplicateEncounters(sameAccount bool) {
 	duplicateEncounters.WithLabelValues(v).Inc()
 }
 
+func (col *promCollector) IncDuplicateProtos(method string) {
+	duplicateProtos.WithLabelValues(method).Inc()
+}
+
+func (col *promCollector) IncStaleUpdates(updateType string) {
+	staleUpdates.WithLabelValues(updateType).Inc()
+}
+
 func (col *promCollector) IncDbQuery(query string, err error) {
 	var status string
 
@@ -566,7 +649,7 @@ func (col *promCollector) DecPokemons(hasIv bool, seenType null.String) {
 
 func initPrometheus() {
 	prometheus.MustRegister(
-		rawRequests, decodeMethods, decodeFortDetails, decodeGetMapForts, decodeGetGymInfo, decodeEncounter,
+		rawRequests, rawSkippedProtos, rawCredentialRequests, decodeQueueDepth, decodeQueueWait, decodeQueueDrops, decodeMethods, decodeFortDetails, decodeGetMapForts, decodeGetGymInfo, decodeEncounter,
 		decodeDiskEncounter, decodeQuest, decodeSocialActionWithRequest, decodeGMO, decodeGMOType,
 		decodeGetFriendDetails, decodeSearchPlayer, decodeOpenInvasion, decodeStartIncident,
 
@@ -576,7 +659,7 @@ func initPrometheus() {
 		pokemonCountShiny, pokemonCountNonShiny, pokemonCountShundo, pokemonCountSnundo,
 
 		verifiedPokemonTTL, verifiedPokemonTTLCounter, raidCount, fortCount, incidentCount,
-		duplicateEncounters, dbQueries,
+		duplicateEncounters, duplicateProtos, staleUpdates, dbQueries,
 
 		gyms, incidents, pokemons, lures, quests, raids,
 	)
@@ -11,6 +11,11 @@ import (
 
 type StatsCollector interface {
 	IncRawRequests(status, message string)
+	AddRawSkippedProtos(format string, value float64)
+	IncRawCredentialRequests(credential, status string)
+	SetDecodeQueueDepth(depth float64)
+	UpdateDecodeQueueWait(seconds float64)
+	IncDecodeQueueDrops(source string)
 	IncDecodeMethods(status, message, method string)
 	IncDecodeFortDetails(status, message string)
 	IncDecodeGetMapForts(status, message string)
@@ -39,6 +44,8 @@ type StatsCollector interface {
 	UpdateFortCount(areas []geo.AreaName, fortType string, changeType string)
 	UpdateIncidentCount(areas []geo.AreaName)
 	IncDuplicateEncounters(sameAccount bool)
+	IncDuplicateProtos(method string)
+	IncStaleUpdates(updateType string)
 	IncDbQuery(query string, err error)
 	SetGyms(teamId int8, inBattle bool, count float64)
 	SetRaids(level int64, count float64)
@@ -26,6 +26,12 @@ const (
 	FortUpdate
 	PokemonIV
 	PokemonNoIV
+	Account
+	Device
+	GymDefenders
+	Station
+	MaxBattle
+	Route
 	// this magically becomes the number of types we have
 	webhookTypesLength
 )
@@ -42,6 +48,12 @@ func init() {
 	webhookTypeToPayloadType[FortUpdate] = "fort_update"
 	webhookTypeToPayloadType[PokemonIV] = "pokemon"
 	webhookTypeToPayloadType[PokemonNoIV] = "pokemon"
+	webhookTypeToPayloadType[Account] = "account"
+	webhookTypeToPayloadType[Device] = "device"
+	webhookTypeToPayloadType[GymDefenders] = "gym_defenders"
+	webhookTypeToPayloadType[Station] = "station"
+	webhookTypeToPayloadType[MaxBattle] = "max_battle"
+	webhookTypeToPayloadType[Route] = "route"
 
 	// if we add more types, make sure one has added everything here
 	for _, str := range webhookTypeToPayloadType {
@@ -62,6 +74,12 @@ var webhookConfigStringToType = map[string][]WebhookType{
 	"pokemon_iv":    []WebhookType{PokemonIV},
 	"pokemon_no_iv": []WebhookType{PokemonNoIV},
 	"pokemon":       []WebhookType{PokemonIV, PokemonNoIV},
+	"account":       []WebhookType{Account},
+	"device":        []WebhookType{Device},
+	"gym_defenders": []WebhookType{GymDefenders},
+	"station":       []WebhookType{Station},
+	"max_battle":    []WebhookType{MaxBattle},
+	"route":         []WebhookType{Route},
 }
 
 type webhook struct {